@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+)
+
+const createAdminUsage = `Usage: server create-admin -email <email> -first-name <name> -last-name <name> -age <age> [-phone <phone>]
+
+Creates a user with the admin role. The password is read from the first line
+of standard input, e.g. printf '%s\n' "$ADMIN_PASSWORD" | server create-admin ...`
+
+// runCreateAdmin creates an administrator in the configured database, which
+// bootstraps access to a server with authentication enabled
+func runCreateAdmin(cfg *config.Config, log *slog.Logger, args []string, stdin io.Reader) error {
+	var req model.CreateUserRequest
+	flags := flag.NewFlagSet("create-admin", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.StringVar(&req.Email, "email", "", "")
+	flags.StringVar(&req.FirstName, "first-name", "", "")
+	flags.StringVar(&req.LastName, "last-name", "", "")
+	flags.IntVar(&req.Age, "age", 0, "")
+	flags.StringVar(&req.Phone, "phone", "", "")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w\n\n%s", err, createAdminUsage)
+	}
+
+	if cfg.Database.Driver == "memory" {
+		return fmt.Errorf("the memory database driver does not keep users between runs")
+	}
+
+	password, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+	req.Password = strings.TrimRight(password, "\r\n")
+
+	store, err := openStorage(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer store.db.Close()
+
+	var opts []service.UserServiceOption
+	if cfg.Events.Enabled {
+		opts = append(opts, service.WithEvents())
+	}
+	users := service.NewUserService(store.users, cfg.Pagination.CursorSecret, log, opts...)
+
+	admin, err := users.CreateAdmin(context.Background(), &req)
+	if err != nil {
+		var serviceErr *service.Error
+		if errors.As(err, &serviceErr) && len(serviceErr.Fields) > 0 {
+			var fields []string
+			for _, field := range slices.Sorted(maps.Keys(serviceErr.Fields)) {
+				fields = append(fields, field+": "+serviceErr.Fields[field])
+			}
+			return fmt.Errorf("%s: %s", serviceErr.Message, strings.Join(fields, "; "))
+		}
+		return err
+	}
+
+	fmt.Printf("Created administrator %d (%s)\n", admin.ID, admin.Email)
+	return nil
+}
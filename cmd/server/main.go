@@ -2,16 +2,29 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/database"
+	"github.com/your-org/your-project/internal/events"
 	"github.com/your-org/your-project/internal/handler"
+	"github.com/your-org/your-project/internal/health"
+	"github.com/your-org/your-project/internal/logger"
+	"github.com/your-org/your-project/internal/metrics"
 	"github.com/your-org/your-project/internal/middleware"
+	"github.com/your-org/your-project/internal/migrate"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+	"github.com/your-org/your-project/internal/service"
+	"github.com/your-org/your-project/internal/tracing"
 
 	"github.com/labstack/echo/v4"
 	echomiddleware "github.com/labstack/echo/v4/middleware"
@@ -21,7 +34,34 @@ func main() {
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Failed to load config: %v", err)
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Create the structured logger used by every component
+	log := logger.New(cfg.Logger, os.Stdout)
+	slog.SetDefault(log)
+
+	// Run migration subcommands instead of the server when requested
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(cfg, os.Args[2:]); err != nil {
+			fatal(log, "Migration failed", err)
+		}
+		return
+	}
+
+	// Create the first administrator instead of running the server when requested
+	if len(os.Args) > 1 && os.Args[1] == "create-admin" {
+		if err := runCreateAdmin(cfg, log, os.Args[2:], os.Stdin); err != nil {
+			fatal(log, "Failed to create administrator", err)
+		}
+		return
+	}
+
+	// Install the tracer provider before any component creates spans
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.App)
+	if err != nil {
+		fatal(log, "Failed to initialize tracing", err)
 	}
 
 	// Create Echo instance
@@ -30,58 +70,386 @@ func main() {
 	// Configure Echo
 	e.HideBanner = true
 	e.HidePort = true
+	e.HTTPErrorHandler = handler.HTTPErrorHandler
+
+	// Initialize storage
+	store, err := openStorage(cfg, log)
+	if err != nil {
+		fatal(log, "Failed to initialize storage", err)
+	}
+
+	var userOptions []service.UserServiceOption
+	if cfg.Events.Enabled {
+		userOptions = append(userOptions, service.WithEvents())
+	}
+	userService := service.NewUserService(store.users, cfg.Pagination.CursorSecret, log, userOptions...)
 
 	// Add middleware
-	e.Use(echomiddleware.Logger())
-	e.Use(echomiddleware.Recover())
-	e.Use(echomiddleware.CORS())
 	e.Use(echomiddleware.RequestID())
+	e.Use(middleware.RoutePath())
+	if cfg.Metrics.Enabled {
+		m := metrics.New(userService)
+		e.Use(m.Middleware())
+		e.GET(cfg.Metrics.Path, m.Handler())
+	}
+	e.Use(middleware.Tracing())
+	e.Use(middleware.RequestLogger(log))
+	e.Use(echomiddleware.RecoverWithConfig(echomiddleware.RecoverConfig{
+		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
+			log.ErrorContext(c.Request().Context(), "panic recovered", "error", err, "stack", string(stack))
+			return err
+		},
+	}))
+	e.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
+		// Exports set their own, longer deadline
+		Skipper: func(c echo.Context) bool { return c.Path() == exportUsersPath },
+		Timeout: cfg.Server.RequestTimeout,
+	}))
+	e.Use(echomiddleware.CORSWithConfig(echomiddleware.CORSConfig{
+		ExposeHeaders: []string{"ETag"},
+	}))
 	e.Use(middleware.Config(cfg))
 
+	apiKeyService := service.NewAPIKeyService(store.apiKeys, log)
+	jobQueue := queue.New(store.queue, cfg.Queue, log)
+	importService := service.NewImportService(userService, store.jobs, jobQueue, log)
+	webhookService := service.NewWebhookService(store.webhooks, jobQueue, cfg.Webhooks, log)
+	healthChecks := newHealthRegistry(cfg.Health, store)
+	options := []handler.Option{
+		handler.WithLogger(log),
+		handler.WithUserService(userService),
+		handler.WithAPIKeyService(apiKeyService),
+		handler.WithImportService(importService),
+		handler.WithWebhookService(webhookService),
+		handler.WithHealth(healthChecks),
+	}
+
+	// Token issuing is available when the JWT config holds a signing key
+	issueTokens := cfg.Auth.Enabled && cfg.Auth.JWT.CanSign()
+	if issueTokens {
+		authService, err := service.NewAuthService(store.users, store.tokens, cfg.Auth, log)
+		if err != nil {
+			fatal(log, "Failed to initialize auth service", err)
+		}
+		options = append(options, handler.WithAuthService(authService))
+	}
+
 	// Initialize handlers
-	h := handler.New(cfg)
+	h := handler.New(cfg, store.users, options...)
+
+	// Authentication and authorization
+	access, err := newAccessControl(cfg, apiKeyService, log)
+	if err != nil {
+		fatal(log, "Failed to initialize access control", err)
+	}
 
 	// Routes
-	setupRoutes(e, h)
+	setupRoutes(e, h, access, cfg)
+	if issueTokens {
+		setupAuthRoutes(e, h)
+	}
+
+	// Background work stopped before the storage is closed
+	background, stopBackground := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	// Running jobs are given queue.drain_timeout to finish
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		jobQueue.Run(background)
+	}()
+
+	if cfg.Events.Enabled {
+		sinks := newEventSinks(cfg.Events)
+		if cfg.Webhooks.Enabled {
+			sinks = append(sinks, webhookService)
+		}
+		relay := events.NewRelay(store.outbox, sinks, cfg.Events, log)
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			relay.Run(background)
+		}()
+	}
+
+	if cfg.Users.DeletedRetention > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			userService.RunPurge(background, cfg.Users.DeletedRetention, cfg.Users.PurgeInterval)
+		}()
+	}
+
+	if cfg.Webhooks.Enabled && cfg.Webhooks.DeliveryRetention > 0 {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			webhookService.RunPurge(background, cfg.Webhooks.PurgeInterval)
+		}()
+	}
 
 	// Start server
 	go func() {
 		addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
-		log.Printf("Server starting on %s", addr)
+		log.Info("Server starting", "addr", addr)
 		if err := e.Start(addr); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
+			fatal(log, "Failed to start server", err)
 		}
 	}()
 
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
-	log.Println("Shutting down server...")
+	log.Info("Shutting down server")
+
+	// Fail readiness first so load balancers stop sending new requests
+	healthChecks.SetDraining()
+	if cfg.Health.DrainDelay > 0 {
+		log.Info("Draining before shutdown", "delay", cfg.Health.DrainDelay)
+		time.Sleep(cfg.Health.DrainDelay)
+	}
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := e.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		fatal(log, "Server forced to shutdown", err)
 	}
 
-	log.Println("Server exited")
+	stopBackground()
+	workers.Wait()
+
+	if store.db != nil {
+		if err := store.db.Close(); err != nil {
+			log.Error("Failed to close database", "error", err)
+		}
+	}
+
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error("Failed to flush traces", "error", err)
+	}
+
+	log.Info("Server exited")
 }
 
-func setupRoutes(e *echo.Echo, h *handler.Handler) {
-	// Health check
-	e.GET("/health", h.Health)
+// fatal logs an error and terminates the process
+func fatal(log *slog.Logger, msg string, err error) {
+	log.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// exportUsersPath is the route of the user export, which streams for longer
+// than the regular request timeout allows
+const exportUsersPath = "/api/v1/users/export"
+
+func setupRoutes(e *echo.Echo, h *handler.Handler, access *accessControl, cfg *config.Config) {
+	// Health checks; /health is kept as an alias of /readyz
+	e.GET("/livez", h.Liveness)
+	e.GET("/readyz", h.Readiness)
+	e.GET("/health", h.Readiness)
 
 	// API v1 group
 	api := e.Group("/api/v1")
 
 	// User routes
-	users := api.Group("/users")
-	users.POST("", h.CreateUser)
-	users.GET("/:id", h.GetUser)
-	users.PUT("/:id", h.UpdateUser)
-	users.DELETE("/:id", h.DeleteUser)
-	users.GET("", h.ListUsers)
+	users := api.Group("/users", access.authenticate)
+	users.POST("", h.CreateUser, access.allow(middleware.ActionCreateUser))
+	users.GET("/:id", h.GetUser, access.allow(middleware.ActionReadUser))
+	users.PUT("/:id", h.ReplaceUser, access.allow(middleware.ActionUpdateUser))
+	users.PATCH("/:id", h.PatchUser, access.allow(middleware.ActionUpdateUser))
+	users.DELETE("/:id", h.DeleteUser, access.allow(middleware.ActionDeleteUser))
+	users.POST("/:id/restore", h.RestoreUser, access.allow(middleware.ActionRestoreUser))
+	users.GET("", h.ListUsers, access.allow(middleware.ActionListUsers))
+	users.GET("/export", h.ExportUsers, access.allow(middleware.ActionListUsers), middleware.Timeout(cfg.Users.ExportTimeout))
+	users.POST("/import", h.ImportUsers, access.allow(middleware.ActionImportUsers))
+
+	// Batch routes; the colon is escaped so it is not read as a path parameter
+	users.POST("\\:batchCreate", h.BatchCreateUsers, access.allow(middleware.ActionCreateUser))
+	users.POST("\\:batchUpdate", h.BatchUpdateUsers, access.allow(middleware.ActionUpdateUser))
+	users.POST("\\:batchDelete", h.BatchDeleteUsers, access.allow(middleware.ActionDeleteUser))
+
+	// API key management routes
+	keys := api.Group("/api-keys", access.authenticate, access.allow(middleware.ActionManageAPIKeys))
+	keys.POST("", h.CreateAPIKey)
+	keys.GET("", h.ListAPIKeys)
+	keys.DELETE("/:id", h.RevokeAPIKey)
+
+	// Webhook management routes; without delivery they are left unregistered
+	if cfg.Webhooks.Enabled {
+		webhooks := api.Group("/webhooks", access.authenticate, access.allow(middleware.ActionManageWebhooks))
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.ListWebhooks)
+		webhooks.GET("/:id", h.GetWebhook)
+		webhooks.PATCH("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.ListWebhookDeliveries)
+	}
+
+	// Background job routes
+	jobs := api.Group("/jobs", access.authenticate)
+	jobs.GET("/:id", h.GetJob, access.allow(middleware.ActionReadJobs))
+}
+
+// setupAuthRoutes registers the unauthenticated token endpoints
+func setupAuthRoutes(e *echo.Echo, h *handler.Handler) {
+	auth := e.Group("/api/v1/auth")
+	auth.POST("/login", h.Login)
+	auth.POST("/refresh", h.Refresh)
+	auth.POST("/logout", h.Logout)
+}
+
+// accessControl holds the authentication and authorization middleware for API routes
+type accessControl struct {
+	authenticate echo.MiddlewareFunc
+	policy       middleware.Policy
+}
+
+// newAccessControl creates the access control for the configured auth settings,
+// accepting bearer tokens and the API keys known to keys.
+// When authentication is disabled every request passes through unchanged.
+func newAccessControl(cfg *config.Config, keys middleware.APIKeyVerifier, log *slog.Logger) (*accessControl, error) {
+	if !cfg.Auth.Enabled {
+		log.Warn("Authentication is disabled")
+		return &accessControl{authenticate: passThrough}, nil
+	}
+
+	authenticator, err := middleware.NewJWTAuthenticator(cfg.Auth.JWT)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := middleware.ParsePolicy(cfg.Auth.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	return &accessControl{
+		authenticate: middleware.Authenticate(authenticator, keys),
+		policy:       policy,
+	}, nil
+}
+
+// allow returns the middleware authorizing action on a route
+func (a *accessControl) allow(action middleware.Action) echo.MiddlewareFunc {
+	if a.policy == nil {
+		return passThrough
+	}
+	return middleware.Authorize(a.policy, action)
+}
+
+// passThrough is a middleware that does nothing
+func passThrough(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
+
+// storage holds the repositories for the configured database driver.
+// db is nil when the in-memory driver is used.
+type storage struct {
+	db       *sql.DB
+	users    repository.UserRepository
+	tokens   repository.RefreshTokenRepository
+	apiKeys  repository.APIKeyRepository
+	jobs     repository.JobRepository
+	queue    repository.QueueRepository
+	outbox   repository.OutboxRepository
+	webhooks repository.WebhookRepository
+}
+
+// openStorage creates the repositories for the configured database driver
+func openStorage(cfg *config.Config, log *slog.Logger) (*storage, error) {
+	if cfg.Database.Driver == "memory" {
+		users := repository.NewMemoryUserRepository()
+		return &storage{
+			users:    repository.NewTracedUserRepository(users, "memory"),
+			tokens:   repository.NewMemoryRefreshTokenRepository(),
+			apiKeys:  repository.NewMemoryAPIKeyRepository(),
+			jobs:     repository.NewMemoryJobRepository(),
+			queue:    repository.NewMemoryQueueRepository(),
+			outbox:   users,
+			webhooks: repository.NewMemoryWebhookRepository(),
+		}, nil
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkMigrations(db, cfg.Database, log); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &storage{
+		db:       db,
+		users:    repository.NewTracedUserRepository(repository.NewSQLUserRepository(db), database.SystemName(cfg.Database.Driver)),
+		tokens:   repository.NewSQLRefreshTokenRepository(db),
+		apiKeys:  repository.NewSQLAPIKeyRepository(db),
+		jobs:     repository.NewSQLJobRepository(db),
+		queue:    repository.NewSQLQueueRepository(db),
+		outbox:   repository.NewSQLOutboxRepository(db),
+		webhooks: repository.NewSQLWebhookRepository(db),
+	}, nil
+}
+
+// newEventSinks creates the configured event sinks
+func newEventSinks(cfg config.EventsConfig) []events.Sink {
+	var sinks []events.Sink
+	if cfg.File.Path != "" {
+		sinks = append(sinks, events.NewFileSink(cfg.File.Path))
+	}
+	if cfg.HTTP.URL != "" {
+		sinks = append(sinks, events.NewHTTPSink(cfg.HTTP))
+	}
+	return sinks
+}
+
+// newHealthRegistry registers the readiness checks for the storage and configured dependencies
+func newHealthRegistry(cfg config.HealthConfig, store *storage) *health.Registry {
+	registry := health.NewRegistry(cfg)
+
+	if store.db != nil {
+		registry.Register("database", health.PingChecker(store.db))
+	}
+
+	if cfg.Disk.Path != "" {
+		registry.Register("disk", health.DiskSpaceChecker(cfg.Disk.Path, uint64(cfg.Disk.MinFreeMB)<<20))
+	}
+
+	for _, dep := range cfg.Dependencies {
+		var opts []health.CheckOption
+		if dep.Timeout > 0 {
+			opts = append(opts, health.WithTimeout(dep.Timeout))
+		}
+		registry.Register(dep.Name, health.HTTPChecker(http.DefaultClient, dep.URL), opts...)
+	}
+
+	return registry
+}
+
+// checkMigrations reports pending migrations, failing when the config requires an up-to-date schema
+func checkMigrations(db *sql.DB, cfg config.DatabaseConfig, log *slog.Logger) error {
+	migrator, err := migrate.New(db, cfg.Driver)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if cfg.RequireMigrations {
+		return fmt.Errorf("%d pending migration(s); run \"migrate up\" before starting the server", len(pending))
+	}
+
+	log.Warn("Database has pending migrations", "count", len(pending))
+	return nil
 }
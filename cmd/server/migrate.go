@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/database"
+	"github.com/your-org/your-project/internal/migrate"
+)
+
+const migrateUsage = `Usage: server migrate <command>
+
+Commands:
+  up        Apply all pending migrations
+  down [N]  Revert the last N applied migrations (default 1)
+  status    Show the state of every migration`
+
+// runMigrate executes a migrate subcommand against the configured database
+func runMigrate(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing migrate command\n\n%s", migrateUsage)
+	}
+
+	if cfg.Database.Driver == "memory" {
+		return fmt.Errorf("the memory database driver does not use migrations")
+	}
+
+	db, err := database.Open(cfg.Database)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := migrate.New(db, cfg.Database.Driver)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		fmt.Printf("Applied %d migration(s)\n", applied)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of migrations to revert: %s", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		fmt.Printf("Reverted %d migration(s)\n", reverted)
+		return err
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		return printStatus(statuses)
+	default:
+		return fmt.Errorf("unknown migrate command: %s\n\n%s", args[0], migrateUsage)
+	}
+}
+
+// printStatus writes a table of migration states to stdout
+func printStatus(statuses []migrate.Status) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, status := range statuses {
+		state, appliedAt := "pending", "-"
+		if status.Applied {
+			state, appliedAt = "applied", status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", status.Version, status.Name, state, appliedAt)
+	}
+	return w.Flush()
+}
@@ -1,35 +1,54 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config holds all configuration for our application
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	App      AppConfig      `mapstructure:"app"`
+	Server     ServerConfig     `mapstructure:"server"`
+	Database   DatabaseConfig   `mapstructure:"database"`
+	Logger     LoggerConfig     `mapstructure:"logger"`
+	Pagination PaginationConfig `mapstructure:"pagination"`
+	Users      UsersConfig      `mapstructure:"users"`
+	Queue      QueueConfig      `mapstructure:"queue"`
+	Events     EventsConfig     `mapstructure:"events"`
+	Webhooks   WebhooksConfig   `mapstructure:"webhooks"`
+	Auth       AuthConfig       `mapstructure:"auth"`
+	Metrics    MetricsConfig    `mapstructure:"metrics"`
+	Tracing    TracingConfig    `mapstructure:"tracing"`
+	Health     HealthConfig     `mapstructure:"health"`
+	App        AppConfig        `mapstructure:"app"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Port int    `mapstructure:"port"`
-	Host string `mapstructure:"host"`
+	Port           int           `mapstructure:"port"`
+	Host           string        `mapstructure:"host"`
+	RequestTimeout time.Duration `mapstructure:"request_timeout"`
 }
 
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Driver   string `mapstructure:"driver"`
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	Username string `mapstructure:"username"`
-	Password string `mapstructure:"password"`
-	Database string `mapstructure:"database"`
-	SSLMode  string `mapstructure:"ssl_mode"`
+	Driver            string        `mapstructure:"driver"`
+	Host              string        `mapstructure:"host"`
+	Port              int           `mapstructure:"port"`
+	Username          string        `mapstructure:"username"`
+	Password          string        `mapstructure:"password"`
+	Database          string        `mapstructure:"database"`
+	SSLMode           string        `mapstructure:"ssl_mode"`
+	MaxOpenConns      int           `mapstructure:"max_open_conns"`
+	MaxIdleConns      int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
+	RequireMigrations bool          `mapstructure:"require_migrations"`
 }
 
 // LoggerConfig holds logger configuration
@@ -38,6 +57,180 @@ type LoggerConfig struct {
 	Format string `mapstructure:"format"`
 }
 
+// PaginationConfig holds list pagination configuration
+type PaginationConfig struct {
+	// CursorSecret signs pagination cursors. A random secret is used when empty,
+	// which invalidates cursors on restart and across instances.
+	CursorSecret string `mapstructure:"cursor_secret"`
+}
+
+// UsersConfig holds user lifecycle configuration
+type UsersConfig struct {
+	// DeletedRetention is how long deleted users can be restored before they are
+	// purged permanently. Zero keeps deleted users forever.
+	DeletedRetention time.Duration `mapstructure:"deleted_retention"`
+	// PurgeInterval is how often users past the retention period are purged
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+	// MaxBatchSize is the largest number of items a batch request may contain
+	MaxBatchSize int `mapstructure:"max_batch_size"`
+	// ExportTimeout replaces server.request_timeout for user exports, which
+	// stream for as long as there are users to send. Zero disables it.
+	ExportTimeout time.Duration `mapstructure:"export_timeout"`
+	Import        ImportConfig  `mapstructure:"import"`
+}
+
+// ImportConfig holds user import configuration
+type ImportConfig struct {
+	// MaxSizeMB is the largest upload accepted, in megabytes
+	MaxSizeMB int `mapstructure:"max_size_mb"`
+}
+
+// QueueConfig holds background job queue configuration
+type QueueConfig struct {
+	// Workers is how many jobs run at the same time on each server
+	Workers int `mapstructure:"workers"`
+	// PollInterval is how often idle workers look for due jobs
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// MaxAttempts is how many times a job runs before it becomes a dead letter,
+	// unless the job sets its own limit
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// RetryBackoff is the delay before the first retry; it doubles with every
+	// further attempt up to MaxRetryBackoff
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+	// JobTimeout bounds a single run of a job. A job whose server stops
+	// responding is taken over by another worker once it has passed.
+	JobTimeout time.Duration `mapstructure:"job_timeout"`
+	// DrainTimeout is how long shutdown waits for running jobs before
+	// cancelling them; cancelled jobs run again without using up an attempt
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
+}
+
+// EventsConfig holds domain event publishing configuration
+type EventsConfig struct {
+	// Enabled records user lifecycle events in the outbox and publishes them to the sinks
+	Enabled bool `mapstructure:"enabled"`
+	// PollInterval is how often the outbox is checked for unpublished events
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// BatchSize is the largest number of events published at once
+	BatchSize int `mapstructure:"batch_size"`
+	// Lease is how long a relay may publish before another server's relay
+	// takes over; only the relay holding the lease publishes
+	Lease time.Duration `mapstructure:"lease"`
+	// Retention is how long published events stay in the outbox. Zero keeps them forever.
+	Retention time.Duration  `mapstructure:"retention"`
+	File      FileSinkConfig `mapstructure:"file"`
+	HTTP      HTTPSinkConfig `mapstructure:"http"`
+}
+
+// FileSinkConfig holds the NDJSON file event sink. The sink is disabled when Path is empty.
+type FileSinkConfig struct {
+	Path string `mapstructure:"path"`
+}
+
+// HTTPSinkConfig holds the HTTP event sink, which POSTs batches of events as
+// NDJSON. The sink is disabled when URL is empty.
+type HTTPSinkConfig struct {
+	URL     string            `mapstructure:"url"`
+	Timeout time.Duration     `mapstructure:"timeout"`
+	Headers map[string]string `mapstructure:"headers"`
+}
+
+// WebhooksConfig holds outgoing webhook delivery configuration
+type WebhooksConfig struct {
+	// Enabled delivers user events to the webhook subscriptions; it requires events.enabled
+	Enabled bool `mapstructure:"enabled"`
+	// MaxAttempts is how many times an event is sent to a webhook before it is given up.
+	// Retries are spaced by the queue's backoff.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Timeout bounds a single delivery request
+	Timeout time.Duration `mapstructure:"timeout"`
+	// DisableAfter disables a webhook after this many failed deliveries in a
+	// row. Zero never disables webhooks.
+	DisableAfter int `mapstructure:"disable_after"`
+	// DeliveryRetention is how long delivery logs are kept. Zero keeps them forever.
+	DeliveryRetention time.Duration `mapstructure:"delivery_retention"`
+	// PurgeInterval is how often delivery logs past the retention period are purged
+	PurgeInterval time.Duration `mapstructure:"purge_interval"`
+	// AllowPrivateNetworks lets webhooks reach loopback, private and link-local
+	// addresses, which are refused by default
+	AllowPrivateNetworks bool `mapstructure:"allow_private_networks"`
+}
+
+// AuthConfig holds authentication configuration
+type AuthConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	JWT             JWTConfig     `mapstructure:"jwt"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+	// Roles maps each role to its granted actions, e.g. "users:delete" or "users:read:own"
+	Roles map[string][]string `mapstructure:"roles"`
+}
+
+// JWTConfig holds bearer token configuration.
+// HS256 tokens are verified with Secret, RS256 tokens with the key in
+// PublicKeyFile or the key matching the token's kid in JWKSFile. Tokens issued
+// by the login endpoints are signed with Secret or PrivateKeyFile.
+type JWTConfig struct {
+	Algorithm      string        `mapstructure:"algorithm"`
+	Secret         string        `mapstructure:"secret"`
+	PublicKeyFile  string        `mapstructure:"public_key_file"`
+	PrivateKeyFile string        `mapstructure:"private_key_file"`
+	KeyID          string        `mapstructure:"key_id"`
+	JWKSFile       string        `mapstructure:"jwks_file"`
+	Issuer         string        `mapstructure:"issuer"`
+	Audience       string        `mapstructure:"audience"`
+	Leeway         time.Duration `mapstructure:"leeway"`
+}
+
+// CanSign reports whether the configuration holds a key for issuing tokens
+func (c *JWTConfig) CanSign() bool {
+	return c.Algorithm == "HS256" || c.PrivateKeyFile != ""
+}
+
+// MetricsConfig holds Prometheus metrics configuration
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+// TracingConfig holds OpenTelemetry tracing configuration.
+// Exporter is "otlp" (OTLP over HTTP to Endpoint), "stdout" or "file" (JSON spans appended to File).
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	File        string  `mapstructure:"file"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// HealthConfig holds health check configuration
+type HealthConfig struct {
+	// Timeout bounds each check that does not set its own timeout
+	Timeout time.Duration `mapstructure:"timeout"`
+	// CacheTTL is how long a check result is reused before the check runs again
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	// DrainDelay keeps serving requests with failing readiness before shutting down,
+	// giving load balancers time to stop routing to the instance
+	DrainDelay   time.Duration      `mapstructure:"drain_delay"`
+	Disk         DiskCheckConfig    `mapstructure:"disk"`
+	Dependencies []DependencyConfig `mapstructure:"dependencies"`
+}
+
+// DiskCheckConfig holds the free disk space check. The check is disabled when Path is empty.
+type DiskCheckConfig struct {
+	Path      string `mapstructure:"path"`
+	MinFreeMB int    `mapstructure:"min_free_mb"`
+}
+
+// DependencyConfig describes a downstream HTTP service whose URL must answer for the server to be ready
+type DependencyConfig struct {
+	Name    string        `mapstructure:"name"`
+	URL     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
 // AppConfig holds general application configuration
 type AppConfig struct {
 	Name        string `mapstructure:"name"`
@@ -83,6 +276,16 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Without a configured secret, development servers sign HS256 tokens with
+	// a random one, so issued tokens stop working on restart
+	if config.App.Environment == "development" && config.Auth.JWT.Algorithm == "HS256" && config.Auth.JWT.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+		config.Auth.JWT.Secret = hex.EncodeToString(secret)
+	}
+
 	// Validate configuration
 	if err := validate(&config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -96,6 +299,7 @@ func setDefaults() {
 	// Server defaults
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.host", "0.0.0.0")
+	viper.SetDefault("server.request_timeout", "30s")
 
 	// Database defaults
 	viper.SetDefault("database.driver", "postgres")
@@ -105,11 +309,91 @@ func setDefaults() {
 	viper.SetDefault("database.password", "postgres")
 	viper.SetDefault("database.database", "app_db")
 	viper.SetDefault("database.ssl_mode", "disable")
+	viper.SetDefault("database.max_open_conns", 25)
+	viper.SetDefault("database.max_idle_conns", 5)
+	viper.SetDefault("database.conn_max_lifetime", "30m")
+	viper.SetDefault("database.conn_max_idle_time", "5m")
+	viper.SetDefault("database.require_migrations", true)
 
 	// Logger defaults
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "json")
 
+	// Pagination defaults
+	viper.SetDefault("pagination.cursor_secret", "")
+
+	// Users defaults
+	viper.SetDefault("users.deleted_retention", "720h")
+	viper.SetDefault("users.purge_interval", "1h")
+	viper.SetDefault("users.max_batch_size", 500)
+	viper.SetDefault("users.export_timeout", "1h")
+	viper.SetDefault("users.import.max_size_mb", 10)
+
+	// Queue defaults
+	viper.SetDefault("queue.workers", 4)
+	viper.SetDefault("queue.poll_interval", "1s")
+	viper.SetDefault("queue.max_attempts", 5)
+	viper.SetDefault("queue.retry_backoff", "1s")
+	viper.SetDefault("queue.max_retry_backoff", "1h")
+	viper.SetDefault("queue.job_timeout", "10m")
+	viper.SetDefault("queue.drain_timeout", "25s")
+
+	// Events defaults
+	viper.SetDefault("events.enabled", false)
+	viper.SetDefault("events.poll_interval", "1s")
+	viper.SetDefault("events.batch_size", 100)
+	viper.SetDefault("events.lease", "30s")
+	viper.SetDefault("events.retention", "168h")
+	viper.SetDefault("events.file.path", "")
+	viper.SetDefault("events.http.url", "")
+	viper.SetDefault("events.http.timeout", "10s")
+
+	// Webhooks defaults
+	viper.SetDefault("webhooks.enabled", false)
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.disable_after", 20)
+	viper.SetDefault("webhooks.delivery_retention", "720h")
+	viper.SetDefault("webhooks.purge_interval", "1h")
+	viper.SetDefault("webhooks.allow_private_networks", false)
+
+	// Auth defaults
+	viper.SetDefault("auth.enabled", false)
+	viper.SetDefault("auth.jwt.algorithm", "HS256")
+	viper.SetDefault("auth.jwt.secret", "")
+	viper.SetDefault("auth.jwt.public_key_file", "")
+	viper.SetDefault("auth.jwt.private_key_file", "")
+	viper.SetDefault("auth.jwt.key_id", "")
+	viper.SetDefault("auth.jwt.jwks_file", "")
+	viper.SetDefault("auth.jwt.issuer", "")
+	viper.SetDefault("auth.jwt.audience", "")
+	viper.SetDefault("auth.jwt.leeway", "30s")
+	viper.SetDefault("auth.access_token_ttl", "15m")
+	viper.SetDefault("auth.refresh_token_ttl", "720h")
+	viper.SetDefault("auth.roles", map[string][]string{
+		"admin": {"users:list", "users:create", "users:read", "users:update", "users:update_status", "users:delete", "users:read_deleted", "users:restore", "users:import", "jobs:read", "api_keys:manage", "webhooks:manage"},
+		"user":  {"users:read:own", "users:update:own"},
+	})
+
+	// Metrics defaults
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+
+	// Tracing defaults
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "stdout")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.file", "traces.json")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
+	// Health defaults
+	viper.SetDefault("health.timeout", "2s")
+	viper.SetDefault("health.cache_ttl", "2s")
+	viper.SetDefault("health.drain_delay", "0s")
+	viper.SetDefault("health.disk.path", "")
+	viper.SetDefault("health.disk.min_free_mb", 100)
+
 	// App defaults
 	viper.SetDefault("app.name", "golang-server-template")
 	viper.SetDefault("app.version", "1.0.0")
@@ -123,6 +407,103 @@ func validate(config *Config) error {
 		return fmt.Errorf("invalid server port: %d", config.Server.Port)
 	}
 
+	if config.Server.RequestTimeout < 0 {
+		return fmt.Errorf("server request timeout cannot be negative")
+	}
+
+	validDrivers := map[string]bool{
+		"postgres": true,
+		"sqlite":   true,
+		"memory":   true,
+	}
+	if !validDrivers[config.Database.Driver] {
+		return fmt.Errorf("invalid database driver: %s", config.Database.Driver)
+	}
+
+	if config.Database.MaxOpenConns < 0 || config.Database.MaxIdleConns < 0 {
+		return fmt.Errorf("database connection pool sizes cannot be negative")
+	}
+
+	validLevels := map[string]bool{
+		"debug": true,
+		"info":  true,
+		"warn":  true,
+		"error": true,
+	}
+	if !validLevels[config.Logger.Level] {
+		return fmt.Errorf("invalid logger level: %s", config.Logger.Level)
+	}
+
+	if config.Logger.Format != "json" && config.Logger.Format != "text" {
+		return fmt.Errorf("invalid logger format: %s", config.Logger.Format)
+	}
+
+	if config.Auth.Enabled {
+		if err := validateJWT(&config.Auth.JWT); err != nil {
+			return err
+		}
+		if config.Auth.AccessTokenTTL <= 0 || config.Auth.RefreshTokenTTL <= 0 {
+			return fmt.Errorf("auth token TTLs must be positive")
+		}
+	}
+
+	if config.Metrics.Enabled && !strings.HasPrefix(config.Metrics.Path, "/") {
+		return fmt.Errorf("metrics path must start with /: %s", config.Metrics.Path)
+	}
+
+	if config.Tracing.Enabled {
+		validExporters := map[string]bool{
+			"otlp":   true,
+			"stdout": true,
+			"file":   true,
+		}
+		if !validExporters[config.Tracing.Exporter] {
+			return fmt.Errorf("invalid tracing exporter: %s", config.Tracing.Exporter)
+		}
+		if config.Tracing.SampleRatio < 0 || config.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing sample ratio must be between 0 and 1")
+		}
+	}
+
+	if config.Users.DeletedRetention < 0 {
+		return fmt.Errorf("users.deleted_retention cannot be negative")
+	}
+	if config.Users.DeletedRetention > 0 && config.Users.PurgeInterval <= 0 {
+		return fmt.Errorf("users.purge_interval must be positive when deleted users are purged")
+	}
+	if config.Users.MaxBatchSize <= 0 {
+		return fmt.Errorf("users.max_batch_size must be positive")
+	}
+	if config.Users.ExportTimeout < 0 {
+		return fmt.Errorf("users.export_timeout cannot be negative")
+	}
+	if config.Users.Import.MaxSizeMB <= 0 {
+		return fmt.Errorf("users.import.max_size_mb must be positive")
+	}
+
+	if err := validateQueue(&config.Queue); err != nil {
+		return err
+	}
+
+	if config.Events.Enabled {
+		if err := validateEvents(&config.Events, config.Webhooks.Enabled); err != nil {
+			return err
+		}
+	}
+
+	if config.Webhooks.Enabled {
+		if !config.Events.Enabled {
+			return fmt.Errorf("webhooks.enabled requires events.enabled")
+		}
+		if err := validateWebhooks(&config.Webhooks); err != nil {
+			return err
+		}
+	}
+
+	if err := validateHealth(&config.Health); err != nil {
+		return err
+	}
+
 	if config.App.Name == "" {
 		return fmt.Errorf("app name cannot be empty")
 	}
@@ -138,3 +519,118 @@ func validate(config *Config) error {
 
 	return nil
 }
+
+// validateJWT validates the bearer token configuration
+func validateJWT(jwt *JWTConfig) error {
+	switch jwt.Algorithm {
+	case "HS256":
+		if jwt.Secret == "" {
+			return fmt.Errorf("auth.jwt.secret is required for HS256 outside development")
+		}
+		if strings.HasPrefix(jwt.Secret, "change-me") {
+			return fmt.Errorf("auth.jwt.secret is a placeholder; set a random secret")
+		}
+		if len(jwt.Secret) < 32 {
+			return fmt.Errorf("auth.jwt.secret must be at least 32 bytes for HS256")
+		}
+	case "RS256":
+		if jwt.PublicKeyFile == "" && jwt.JWKSFile == "" {
+			return fmt.Errorf("auth.jwt.public_key_file or auth.jwt.jwks_file is required for RS256")
+		}
+	default:
+		return fmt.Errorf("invalid auth.jwt.algorithm: %s", jwt.Algorithm)
+	}
+
+	return nil
+}
+
+// validateQueue validates the background job queue configuration
+func validateQueue(queue *QueueConfig) error {
+	if queue.Workers <= 0 || queue.MaxAttempts <= 0 {
+		return fmt.Errorf("queue.workers and queue.max_attempts must be positive")
+	}
+
+	if queue.PollInterval <= 0 || queue.RetryBackoff <= 0 || queue.JobTimeout <= 0 {
+		return fmt.Errorf("queue.poll_interval, queue.retry_backoff and queue.job_timeout must be positive")
+	}
+
+	if queue.MaxRetryBackoff < queue.RetryBackoff {
+		return fmt.Errorf("queue.max_retry_backoff cannot be less than queue.retry_backoff")
+	}
+
+	if queue.DrainTimeout < 0 {
+		return fmt.Errorf("queue.drain_timeout cannot be negative")
+	}
+
+	return nil
+}
+
+// validateEvents validates the event publishing configuration. Webhooks count
+// as a sink when they are enabled.
+func validateEvents(events *EventsConfig, webhooks bool) error {
+	if events.PollInterval <= 0 || events.BatchSize <= 0 || events.Lease <= 0 {
+		return fmt.Errorf("events.poll_interval, events.batch_size and events.lease must be positive")
+	}
+
+	if events.Retention < 0 {
+		return fmt.Errorf("events.retention cannot be negative")
+	}
+
+	if events.File.Path == "" && events.HTTP.URL == "" && !webhooks {
+		return fmt.Errorf("events.file.path, events.http.url or webhooks.enabled is required when events are enabled")
+	}
+
+	if events.HTTP.URL != "" {
+		u, err := url.Parse(events.HTTP.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("events.http.url must be an absolute http or https URL")
+		}
+		if events.HTTP.Timeout <= 0 {
+			return fmt.Errorf("events.http.timeout must be positive")
+		}
+		if events.HTTP.Timeout >= events.Lease {
+			return fmt.Errorf("events.http.timeout must be shorter than events.lease")
+		}
+	}
+
+	return nil
+}
+
+// validateWebhooks validates the webhook delivery configuration
+func validateWebhooks(webhooks *WebhooksConfig) error {
+	if webhooks.MaxAttempts <= 0 || webhooks.Timeout <= 0 {
+		return fmt.Errorf("webhooks.max_attempts and webhooks.timeout must be positive")
+	}
+
+	if webhooks.DisableAfter < 0 || webhooks.DeliveryRetention < 0 {
+		return fmt.Errorf("webhooks.disable_after and webhooks.delivery_retention cannot be negative")
+	}
+
+	if webhooks.DeliveryRetention > 0 && webhooks.PurgeInterval <= 0 {
+		return fmt.Errorf("webhooks.purge_interval must be positive when delivery logs are purged")
+	}
+
+	return nil
+}
+
+// validateHealth validates the health check configuration
+func validateHealth(health *HealthConfig) error {
+	if health.Timeout < 0 || health.CacheTTL < 0 || health.DrainDelay < 0 {
+		return fmt.Errorf("health check durations cannot be negative")
+	}
+
+	if health.Disk.Path != "" && health.Disk.MinFreeMB < 0 {
+		return fmt.Errorf("health.disk.min_free_mb cannot be negative")
+	}
+
+	for i, dep := range health.Dependencies {
+		if dep.Name == "" || dep.URL == "" {
+			return fmt.Errorf("health.dependencies[%d] requires a name and url", i)
+		}
+		if dep.Timeout < 0 {
+			return fmt.Errorf("health.dependencies[%d] timeout cannot be negative", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the "pgx" driver
+)
+
+// pingTimeout bounds how long Open waits for the database to respond
+const pingTimeout = 5 * time.Second
+
+// DriverName returns the database/sql driver name for the configured driver
+func DriverName(driver string) (string, error) {
+	switch driver {
+	case "postgres":
+		return "pgx", nil
+	case "sqlite":
+		return "sqlite3", nil
+	default:
+		return "", fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// SystemName returns the OpenTelemetry db.system name of the configured driver
+func SystemName(driver string) string {
+	if driver == "postgres" {
+		return "postgresql"
+	}
+	return driver
+}
+
+// DSN builds the data source name for the configured driver
+func DSN(cfg config.DatabaseConfig) (string, error) {
+	switch cfg.Driver {
+	case "postgres":
+		query := url.Values{}
+		if cfg.SSLMode != "" {
+			query.Set("sslmode", cfg.SSLMode)
+		}
+
+		dsn := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.Username, cfg.Password),
+			Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+			Path:     "/" + cfg.Database,
+			RawQuery: query.Encode(),
+		}
+		return dsn.String(), nil
+	case "sqlite":
+		query := url.Values{}
+		query.Set("_foreign_keys", "on")
+		query.Set("_busy_timeout", "5000")
+		return "file:" + cfg.Database + "?" + query.Encode(), nil
+	default:
+		return "", fmt.Errorf("unsupported database driver: %s", cfg.Driver)
+	}
+}
+
+// Open opens a connection pool for the configured database and verifies it is reachable
+func Open(cfg config.DatabaseConfig) (*sql.DB, error) {
+	driverName, err := DriverName(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn, err := DSN(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// Configure connection pool
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	return db, nil
+}
+
+// IsUniqueViolation reports whether err was caused by a unique constraint violation
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505"
+	}
+
+	return isSQLiteUniqueViolation(err)
+}
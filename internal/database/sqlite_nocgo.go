@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package database
+
+// Without cgo the "sqlite3" driver is a stub whose connections fail to open,
+// so the sqlite driver is reported as unavailable at startup
+import _ "github.com/mattn/go-sqlite3"
+
+// isSQLiteUniqueViolation always reports false since SQLite is unavailable without cgo
+func isSQLiteUniqueViolation(err error) bool {
+	return false
+}
@@ -0,0 +1,161 @@
+// Package events publishes the domain events stored in the outbox to sinks
+// such as an NDJSON file or an HTTP endpoint. Delivery is at least once: a
+// batch that fails on any sink is offered to every sink again. When several
+// servers share a database, the relay holding the outbox lease publishes and
+// the others stand by.
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// purgeInterval is how often published events past the retention are removed
+const purgeInterval = time.Hour
+
+// tracer creates the publishing spans
+var tracer = otel.Tracer("github.com/your-org/your-project/internal/events")
+
+// Sink receives published events. Publish must either accept the whole batch
+// or return an error, in which case the batch is published again later.
+type Sink interface {
+	// Name identifies the sink in logs and errors
+	Name() string
+	Publish(ctx context.Context, events []*model.Event) error
+}
+
+// Relay moves events from the outbox to the sinks in the order they were stored
+type Relay struct {
+	// id identifies the relay as the holder of the outbox lease
+	id     string
+	outbox repository.OutboxRepository
+	sinks  []Sink
+	cfg    config.EventsConfig
+	logger *slog.Logger
+}
+
+// NewRelay creates a new relay publishing the events in outbox to sinks
+func NewRelay(outbox repository.OutboxRepository, sinks []Sink, cfg config.EventsConfig, logger *slog.Logger) *Relay {
+	return &Relay{
+		id:     newRelayID(),
+		outbox: outbox,
+		sinks:  sinks,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run publishes pending events every poll interval until ctx is cancelled and
+// purges published events past the retention period
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var lastPurge time.Time
+	for {
+		// Work through a backlog without waiting between full batches
+		for {
+			published, err := r.Publish(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					r.logger.ErrorContext(ctx, "failed to publish events", "error", err)
+				}
+				break
+			}
+			if published < r.cfg.BatchSize {
+				break
+			}
+		}
+
+		if r.cfg.Retention > 0 && time.Since(lastPurge) >= purgeInterval {
+			lastPurge = time.Now()
+			if _, err := r.outbox.PurgePublished(ctx, lastPurge.Add(-r.cfg.Retention)); err != nil && ctx.Err() == nil {
+				r.logger.ErrorContext(ctx, "failed to purge published events", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish delivers the next batch of pending events to every sink and
+// returns how many events were published. It publishes nothing while another
+// relay holds the outbox lease.
+func (r *Relay) Publish(ctx context.Context) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "Relay.Publish")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	leased, err := r.outbox.Lease(ctx, r.id, time.Now(), r.cfg.Lease)
+	if err != nil || !leased {
+		return 0, err
+	}
+
+	// Give up before the lease expires and another relay takes the batch over
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Lease)
+	defer cancel()
+
+	events, err := r.outbox.Pending(ctx, r.cfg.BatchSize)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+	span.SetAttributes(attribute.Int("events.count", len(events)))
+
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, events); err != nil {
+			return 0, fmt.Errorf("%s sink: %w", sink.Name(), err)
+		}
+	}
+
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	if err := r.outbox.MarkPublished(ctx, ids, time.Now()); err != nil {
+		return 0, err
+	}
+
+	return len(events), nil
+}
+
+// encodeNDJSON renders events as one JSON object per line
+func encodeNDJSON(events []*model.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// newRelayID returns a random relay ID
+func newRelayID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,226 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink collects published events and fails while err is set
+type recordingSink struct {
+	mutex  sync.Mutex
+	events []*model.Event
+	err    error
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(ctx context.Context, events []*model.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *recordingSink) ids() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ids := make([]string, len(s.events))
+	for i, event := range s.events {
+		ids[i] = event.ID
+	}
+	return ids
+}
+
+func appendEvents(t *testing.T, repo repository.UserRepository, ids ...string) {
+	t.Helper()
+
+	for _, id := range ids {
+		require.NoError(t, repo.AppendEvents(context.Background(), &model.Event{
+			ID: id, Type: model.EventUserCreated, UserID: 1, OccurredAt: time.Now(), Data: json.RawMessage(`{}`),
+		}))
+	}
+}
+
+func TestRelayPublish(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	appendEvents(t, repo, "a", "b", "c")
+
+	good := &recordingSink{}
+	flaky := &recordingSink{err: errors.New("unavailable")}
+	relay := NewRelay(repo, []Sink{good, flaky}, config.EventsConfig{BatchSize: 2, Lease: time.Minute}, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	// A failing sink keeps the batch pending, so it is published again
+	_, err := relay.Publish(ctx)
+	require.ErrorContains(t, err, "recording sink: unavailable")
+
+	flaky.err = nil
+	published, err := relay.Publish(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+	published, err = relay.Publish(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+	published, err = relay.Publish(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	assert.Equal(t, []string{"a", "b", "a", "b", "c"}, good.ids())
+	assert.Equal(t, []string{"a", "b", "c"}, flaky.ids())
+}
+
+func TestRelayLease(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	appendEvents(t, repo, "a", "b", "c")
+
+	cfg := config.EventsConfig{BatchSize: 2, Lease: time.Minute}
+	first := &recordingSink{}
+	second := &recordingSink{}
+	leader := NewRelay(repo, []Sink{first}, cfg, slog.New(slog.DiscardHandler))
+	standby := NewRelay(repo, []Sink{second}, cfg, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	published, err := leader.Publish(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, published)
+
+	// Another server's relay publishes nothing while the lease is held
+	published, err = standby.Publish(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	published, err = leader.Publish(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	assert.Equal(t, []string{"a", "b", "c"}, first.ids())
+	assert.Empty(t, second.ids())
+}
+
+func TestRelayRun(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	appendEvents(t, repo, "a", "b", "c")
+
+	sink := &recordingSink{}
+	relay := NewRelay(repo, []Sink{sink}, config.EventsConfig{
+		PollInterval: 5 * time.Millisecond,
+		BatchSize:    2,
+		Lease:        time.Minute,
+		Retention:    time.Hour,
+	}, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Eventually(t, func() bool { return len(sink.ids()) == 3 }, time.Second, 5*time.Millisecond)
+
+	appendEvents(t, repo, "d")
+	assert.Eventually(t, func() bool { return len(sink.ids()) == 4 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, sink.ids())
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	sink := NewFileSink(path)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, sink.Publish(ctx, []*model.Event{{ID: id, Type: model.EventUserDeleted, Data: json.RawMessage(`{"user_id":1}`)}}))
+	}
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var ids []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event model.Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		assert.Equal(t, model.EventUserDeleted, event.Type)
+		assert.JSONEq(t, `{"user_id":1}`, string(event.Data))
+		ids = append(ids, event.ID)
+	}
+	require.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
+
+func TestHTTPSink(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		bodies []string
+		status = http.StatusAccepted
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		bodies = append(bodies, string(body))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(config.HTTPSinkConfig{
+		URL:     server.URL,
+		Timeout: time.Second,
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+	})
+	ctx := context.Background()
+	events := []*model.Event{
+		{ID: "a", Type: model.EventUserCreated, Data: json.RawMessage(`{}`)},
+		{ID: "b", Type: model.EventUserUpdated, Data: json.RawMessage(`{}`)},
+	}
+
+	require.NoError(t, sink.Publish(ctx, events))
+
+	mutex.Lock()
+	status = http.StatusServiceUnavailable
+	mutex.Unlock()
+	assert.ErrorContains(t, sink.Publish(ctx, events), "unexpected status 503")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, bodies, 2)
+	expected, err := encodeNDJSON(events)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), bodies[0])
+}
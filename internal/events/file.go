@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// FileSink appends events to a local file as NDJSON, one event per line
+type FileSink struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// NewFileSink creates a sink appending to the file at path, which is created when missing
+func NewFileSink(path string) *FileSink {
+	return &FileSink{
+		path: path,
+	}
+}
+
+// Name returns "file"
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+// Publish appends the events and syncs the file to disk
+func (s *FileSink) Publish(ctx context.Context, events []*model.Event) error {
+	data, err := encodeNDJSON(events)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
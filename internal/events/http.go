@@ -0,0 +1,63 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+)
+
+// HTTPSink POSTs each batch of events to a URL as NDJSON. Any 2xx response
+// acknowledges the batch.
+type HTTPSink struct {
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPSink creates a sink for the configured endpoint
+func NewHTTPSink(cfg config.HTTPSinkConfig) *HTTPSink {
+	return &HTTPSink{
+		url:     cfg.URL,
+		headers: cfg.Headers,
+		client:  &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Name returns "http"
+func (s *HTTPSink) Name() string {
+	return "http"
+}
+
+// Publish sends the events in a single request
+func (s *HTTPSink) Publish(ctx context.Context, events []*model.Event) error {
+	data, err := encodeNDJSON(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for name, value := range s.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/your-org/your-project/internal/middleware"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAPIKey issues a new API key
+func (h *Handler) CreateAPIKey(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.CreateAPIKey")
+	defer span.End()
+
+	var req model.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	if err := middleware.ValidateScopes(req.Scopes); err != nil {
+		return service.ValidationError(map[string]string{"Scopes": err.Error()})
+	}
+
+	key, err := h.apiKeyService.CreateAPIKey(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys returns every issued API key
+func (h *Handler) ListAPIKeys(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ListAPIKeys")
+	defer span.End()
+
+	keys, err := h.apiKeyService.ListAPIKeys(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key by ID
+func (h *Handler) RevokeAPIKey(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.RevokeAPIKey")
+	defer span.End()
+
+	if err := h.apiKeyService.RevokeAPIKey(ctx, c.Param("id")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
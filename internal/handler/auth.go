@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/your-org/your-project/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Login signs a user in with email and password and returns a token pair
+func (h *Handler) Login(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.Login")
+	defer span.End()
+
+	var req model.LoginRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	tokens, err := h.authService.Login(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (h *Handler) Refresh(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.Refresh")
+	defer span.End()
+
+	var req model.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	tokens, err := h.authService.Refresh(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes the session of a refresh token
+func (h *Handler) Logout(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.Logout")
+	defer span.End()
+
+	var req model.RefreshTokenRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	if err := h.authService.Logout(ctx, &req); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
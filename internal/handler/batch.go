@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-project/internal/middleware"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+)
+
+// BatchCreateUsers creates several users in one request
+func (h *Handler) BatchCreateUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.BatchCreateUsers")
+	defer span.End()
+
+	var req model.BatchCreateUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	if err := h.checkBatchSize("Users", len(req.Users)); err != nil {
+		return err
+	}
+
+	results, err := h.userService.BatchCreateUsers(ctx, req.Users, req.Atomic)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, batchResponse(c, results, http.StatusCreated, nil))
+}
+
+// BatchUpdateUsers updates several users in one request
+func (h *Handler) BatchUpdateUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.BatchUpdateUsers")
+	defer span.End()
+
+	var req model.BatchUpdateUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	if err := h.checkBatchSize("Users", len(req.Users)); err != nil {
+		return err
+	}
+
+	allowStatusChange := middleware.Can(c, middleware.ActionUpdateUserStatus)
+	results, err := h.userService.BatchUpdateUsers(ctx, req.Users, req.Atomic, allowStatusChange)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, batchResponse(c, results, http.StatusOK, func(i int) int {
+		return req.Users[i].ID
+	}))
+}
+
+// BatchDeleteUsers deletes several users in one request
+func (h *Handler) BatchDeleteUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.BatchDeleteUsers")
+	defer span.End()
+
+	var req model.BatchDeleteUsersRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	if err := h.checkBatchSize("IDs", len(req.IDs)); err != nil {
+		return err
+	}
+
+	results, err := h.userService.BatchDeleteUsers(ctx, req.IDs, req.Atomic)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, batchResponse(c, results, http.StatusNoContent, func(i int) int {
+		return req.IDs[i]
+	}))
+}
+
+// checkBatchSize rejects empty batches and batches over the configured maximum
+func (h *Handler) checkBatchSize(field string, size int) error {
+	switch {
+	case size == 0:
+		return service.ValidationError(map[string]string{field: "Must contain at least 1 item"})
+	case size > h.config.Users.MaxBatchSize:
+		return service.ValidationError(map[string]string{field: fmt.Sprintf("Must contain at most %d items", h.config.Users.MaxBatchSize)})
+	}
+	return nil
+}
+
+// batchResponse reports the outcome of every item, giving successful items the
+// status and failed items the status of the equivalent single request.
+// requestedID returns the ID an item addressed, when there is one.
+func batchResponse(c echo.Context, results []service.BatchResult, status int, requestedID func(i int) int) model.BatchResponse {
+	response := model.BatchResponse{Results: make([]model.BatchResult, len(results))}
+	for i, result := range results {
+		item := model.BatchResult{Index: i, Status: status, User: result.User}
+		switch {
+		case result.User != nil:
+			item.ID = result.User.ID
+		case requestedID != nil:
+			item.ID = requestedID(i)
+		}
+
+		if result.Err != nil {
+			apiErr := classify(result.Err)
+			problem := apiErr.problem(c)
+			item.Status = apiErr.status
+			item.Error = &problem
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+
+		response.Results[i] = item
+	}
+	return response
+}
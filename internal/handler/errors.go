@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Errors returned by handlers for malformed or disallowed requests
+var (
+	errInvalidPayload       = echo.NewHTTPError(http.StatusBadRequest, "Invalid request payload")
+	errInvalidUserID        = echo.NewHTTPError(http.StatusBadRequest, "Invalid user ID")
+	errReadDeletedForbidden = echo.NewHTTPError(http.StatusForbidden, "Only administrators may view deleted users")
+	errInvalidIfMatch       = echo.NewHTTPError(http.StatusBadRequest, "If-Match must be * or a single ETag")
+	errUnsupportedPatch     = echo.NewHTTPError(http.StatusUnsupportedMediaType, "Patch must be application/merge-patch+json or application/json-patch+json")
+	errPatchTooLarge        = echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Patch document is too large")
+	errUnsupportedImport    = echo.NewHTTPError(http.StatusUnsupportedMediaType, "Import must be text/csv or application/x-ndjson")
+	errImportTooLarge       = echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Import file is too large")
+)
+
+// apiError is the transport-neutral description of an error response
+type apiError struct {
+	status      int
+	problemType string
+	title       string
+	detail      string
+	fields      map[string]string
+}
+
+// HTTPErrorHandler translates errors returned by handlers into error responses.
+// Service errors are mapped by kind, echo.HTTPErrors keep their status code,
+// requests whose context expired or was cancelled are reported as 504 and 503
+// and anything else is reported as an internal server error. Clients that
+// accept application/problem+json receive RFC 7807 problem details, everyone
+// else the legacy ErrorResponse shape. The error itself is logged by the
+// RequestLogger middleware.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	apiErr := classify(err)
+
+	var writeErr error
+	switch {
+	case c.Request().Method == http.MethodHead:
+		writeErr = c.NoContent(apiErr.status)
+	case acceptsProblem(c.Request()):
+		c.Response().Header().Set(echo.HeaderContentType, model.MIMEApplicationProblemJSON)
+		writeErr = c.JSON(apiErr.status, apiErr.problem(c))
+	default:
+		writeErr = c.JSON(apiErr.status, apiErr.legacy())
+	}
+	if writeErr != nil {
+		slog.ErrorContext(c.Request().Context(), "failed to write error response", "error", writeErr)
+	}
+}
+
+// classify maps an error to its HTTP status code and description
+func classify(err error) apiError {
+	// A request cut short by its deadline or by the client fails as a whole,
+	// whatever error the interrupted operation reported
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return apiError{
+			status:      http.StatusGatewayTimeout,
+			problemType: "/problems/timeout",
+			title:       "Request timed out",
+			detail:      "The request did not complete within the server's time limit",
+		}
+	case errors.Is(err, context.Canceled):
+		return apiError{
+			status:      http.StatusServiceUnavailable,
+			problemType: "/problems/unavailable",
+			title:       "Request cancelled",
+			detail:      "The request was cancelled before it completed",
+		}
+	}
+
+	var serviceErr *service.Error
+	if errors.As(err, &serviceErr) {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return apiError{
+				status:      http.StatusNotFound,
+				problemType: "/problems/not-found",
+				title:       "Resource not found",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrConflict):
+			return apiError{
+				status:      http.StatusConflict,
+				problemType: "/problems/conflict",
+				title:       "Resource conflict",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrForbidden):
+			return apiError{
+				status:      http.StatusForbidden,
+				problemType: "/problems/forbidden",
+				title:       "Forbidden",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrPreconditionFailed):
+			return apiError{
+				status:      http.StatusPreconditionFailed,
+				problemType: "/problems/precondition-failed",
+				title:       "Precondition failed",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrAborted):
+			return apiError{
+				status:      http.StatusFailedDependency,
+				problemType: "/problems/aborted",
+				title:       "Not applied",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrUnauthorized):
+			return apiError{
+				status:      http.StatusUnauthorized,
+				problemType: "/problems/unauthorized",
+				title:       "Authentication failed",
+				detail:      serviceErr.Message,
+			}
+		case errors.Is(err, service.ErrValidation):
+			return apiError{
+				status:      http.StatusBadRequest,
+				problemType: "/problems/validation-error",
+				title:       serviceErr.Message,
+				fields:      serviceErr.Fields,
+			}
+		}
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		apiErr := apiError{
+			status:      httpErr.Code,
+			problemType: "about:blank",
+			title:       http.StatusText(httpErr.Code),
+		}
+		if msg, ok := httpErr.Message.(string); ok && msg != apiErr.title {
+			apiErr.detail = msg
+		}
+		return apiErr
+	}
+
+	return apiError{
+		status:      http.StatusInternalServerError,
+		problemType: "about:blank",
+		title:       http.StatusText(http.StatusInternalServerError),
+	}
+}
+
+// legacy renders the error in the ErrorResponse shape
+func (e apiError) legacy() model.ErrorResponse {
+	response := model.ErrorResponse{Error: e.title}
+	if e.detail != "" {
+		response.Error = e.detail
+	}
+	if e.fields != nil {
+		response.Details = map[string]interface{}{"validation_errors": e.fields}
+	}
+	return response
+}
+
+// problem renders the error as RFC 7807 problem details
+func (e apiError) problem(c echo.Context) model.ProblemDetails {
+	return model.ProblemDetails{
+		Type:     e.problemType,
+		Title:    e.title,
+		Status:   e.status,
+		Detail:   e.detail,
+		Instance: c.Response().Header().Get(echo.HeaderXRequestID),
+		Errors:   e.fields,
+	}
+}
+
+// acceptsProblem reports whether the Accept header asks for problem details
+func acceptsProblem(r *http.Request) bool {
+	for _, accept := range r.Header.Values(echo.HeaderAccept) {
+		for _, mediaRange := range strings.Split(accept, ",") {
+			mediaType, params, err := mime.ParseMediaType(mediaRange)
+			if err != nil || mediaType != model.MIMEApplicationProblemJSON {
+				continue
+			}
+			// An explicit q=0 means "not acceptable"
+			if q, err := strconv.ParseFloat(params["q"], 64); err == nil && q == 0 {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Conditional request headers
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// etag returns the entity tag of a user at the given version
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// setETag sets the ETag response header for a user at the given version
+func setETag(c echo.Context, version int) {
+	c.Response().Header().Set(headerETag, etag(version))
+}
+
+// ifMatchVersion returns the user version an If-Match header requires, or 0
+// when the request is unconditional. Only "*" and a single strong ETag are
+// supported; a weak or unparsable tag can never match and yields -1.
+func ifMatchVersion(c echo.Context) (int, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+	if strings.Contains(header, ",") {
+		return 0, errInvalidIfMatch
+	}
+
+	version, ok := parseETag(header)
+	if !ok {
+		return -1, nil
+	}
+	return version, nil
+}
+
+// notModified reports whether the If-None-Match header matches the user's
+// current version, in which case a 304 response is written
+func notModified(c echo.Context, version int) (bool, error) {
+	header := c.Request().Header.Get(headerIfNoneMatch)
+	if header == "" {
+		return false, nil
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "*" {
+			// If-None-Match uses weak comparison
+			v, ok := parseETag(strings.TrimPrefix(tag, "W/"))
+			if !ok || v != version {
+				continue
+			}
+		}
+
+		setETag(c, version)
+		return true, c.NoContent(http.StatusNotModified)
+	}
+
+	return false, nil
+}
+
+// parseETag parses a strong entity tag created by etag
+func parseETag(tag string) (int, bool) {
+	unquoted, ok := strings.CutPrefix(tag, `"`)
+	if !ok {
+		return 0, false
+	}
+	unquoted, ok = strings.CutSuffix(unquoted, `"`)
+	if !ok {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+)
+
+// exportFlushEvery is how many users are written between flushes of the response
+const exportFlushEvery = 100
+
+// exportFormat describes one of the formats users can be exported in
+type exportFormat struct {
+	contentType string
+	extension   string
+	newEncoder  func(w io.Writer) userEncoder
+}
+
+// exportFormats maps the format query parameter to its export format
+var exportFormats = map[string]exportFormat{
+	"csv": {
+		contentType: "text/csv; charset=utf-8",
+		extension:   "csv",
+		newEncoder:  newCSVUserEncoder,
+	},
+	"ndjson": {
+		contentType: "application/x-ndjson",
+		extension:   "ndjson",
+		newEncoder:  newNDJSONUserEncoder,
+	},
+}
+
+// ExportUsers streams every user matching the ListUsers filters as CSV or
+// NDJSON. The response is written in chunks as users are read from storage,
+// so an error after the first chunk can only cut the export short.
+func (h *Handler) ExportUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ExportUsers")
+	defer span.End()
+
+	name := c.QueryParam("format")
+	if name == "" {
+		name = "csv"
+	}
+	format, ok := exportFormats[name]
+	if !ok {
+		return service.ValidationError(map[string]string{"Format": "Must be one of: csv ndjson"})
+	}
+
+	var query model.UserListQuery
+	if err := parseUserFilters(c, &query); err != nil {
+		return err
+	}
+
+	includeDeleted, err := includeDeletedParam(c)
+	if err != nil {
+		return err
+	}
+	query.IncludeDeleted = includeDeleted
+
+	// The response starts with the first user, so invalid queries are still
+	// reported with an error status
+	response := c.Response()
+	encoder := format.newEncoder(response)
+	written := 0
+	begin := func() error {
+		response.Header().Set(echo.HeaderContentType, format.contentType)
+		response.Header().Set(echo.HeaderContentDisposition, `attachment; filename="users.`+format.extension+`"`)
+		response.WriteHeader(http.StatusOK)
+		return encoder.Begin()
+	}
+
+	err = h.userService.ExportUsers(ctx, &query, func(user *model.User) error {
+		if written == 0 {
+			if err := begin(); err != nil {
+				return err
+			}
+		}
+
+		if err := encoder.Encode(user); err != nil {
+			return err
+		}
+
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := encoder.Flush(); err != nil {
+				return err
+			}
+			response.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if written == 0 {
+		if err := begin(); err != nil {
+			return err
+		}
+	}
+
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	response.Flush()
+	return nil
+}
+
+// userEncoder writes exported users in one format
+type userEncoder interface {
+	// Begin writes what precedes the first user
+	Begin() error
+	// Encode writes one user
+	Encode(user *model.User) error
+	// Flush writes any buffered output
+	Flush() error
+}
+
+// csvHeader names the exported CSV columns
+var csvHeader = []string{
+	"id", "email", "first_name", "last_name", "age", "phone", "status", "role",
+	"version", "created_at", "updated_at", "deleted_at",
+}
+
+// csvUserEncoder writes users as CSV rows below a header row
+type csvUserEncoder struct {
+	w   *csv.Writer
+	row []string
+}
+
+// newCSVUserEncoder creates a CSV encoder writing to w
+func newCSVUserEncoder(w io.Writer) userEncoder {
+	return &csvUserEncoder{w: csv.NewWriter(w), row: make([]string, len(csvHeader))}
+}
+
+// Begin writes the header row
+func (e *csvUserEncoder) Begin() error {
+	return e.w.Write(csvHeader)
+}
+
+// Encode writes one user as a row
+func (e *csvUserEncoder) Encode(user *model.User) error {
+	deletedAt := ""
+	if user.DeletedAt != nil {
+		deletedAt = user.DeletedAt.UTC().Format(time.RFC3339)
+	}
+
+	e.row = append(e.row[:0],
+		strconv.Itoa(user.ID), user.Email, user.FirstName, user.LastName, strconv.Itoa(user.Age),
+		user.Phone, user.Status, user.Role, strconv.Itoa(user.Version),
+		user.CreatedAt.UTC().Format(time.RFC3339), user.UpdatedAt.UTC().Format(time.RFC3339), deletedAt,
+	)
+	for i, cell := range e.row {
+		e.row[i] = escapeFormula(cell)
+	}
+	return e.w.Write(e.row)
+}
+
+// escapeFormula prefixes cells that spreadsheets would evaluate as a formula
+// with a quote, so user-supplied values open as plain text. Signed numbers,
+// such as E.164 phone numbers, cannot hold a formula and are kept as they are.
+func escapeFormula(cell string) string {
+	if cell == "" {
+		return cell
+	}
+
+	switch cell[0] {
+	case '=', '@', '\t', '\r':
+		return "'" + cell
+	case '+', '-':
+		if !isPlainNumber(cell[1:]) {
+			return "'" + cell
+		}
+	}
+	return cell
+}
+
+// isPlainNumber reports whether s is made of digits with at most one decimal point
+func isPlainNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	point := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] >= '0' && s[i] <= '9':
+		case s[i] == '.' && !point:
+			point = true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Flush writes the buffered rows
+func (e *csvUserEncoder) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// ndjsonUserEncoder writes users as one JSON object per line
+type ndjsonUserEncoder struct {
+	enc *json.Encoder
+}
+
+// newNDJSONUserEncoder creates an NDJSON encoder writing to w
+func newNDJSONUserEncoder(w io.Writer) userEncoder {
+	return &ndjsonUserEncoder{enc: json.NewEncoder(w)}
+}
+
+// Begin writes nothing; NDJSON has no header
+func (e *ndjsonUserEncoder) Begin() error {
+	return nil
+}
+
+// Encode writes one user followed by a newline
+func (e *ndjsonUserEncoder) Encode(user *model.User) error {
+	return e.enc.Encode(user)
+}
+
+// Flush does nothing; every user is written as it is encoded
+func (e *ndjsonUserEncoder) Flush() error {
+	return nil
+}
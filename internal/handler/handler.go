@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/health"
+	"github.com/your-org/your-project/internal/middleware"
 	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
 	"github.com/your-org/your-project/internal/service"
 
 	"github.com/labstack/echo/v4"
@@ -14,168 +20,419 @@ import (
 
 // Handler contains all the handlers
 type Handler struct {
-	config      *config.Config
-	userService *service.UserService
+	config         *config.Config
+	userService    *service.UserService
+	authService    *service.AuthService
+	apiKeyService  *service.APIKeyService
+	importService  *service.ImportService
+	webhookService *service.WebhookService
+	health         *health.Registry
+	logger         *slog.Logger
 }
 
-// New creates a new handler instance
-func New(cfg *config.Config) *Handler {
-	return &Handler{
-		config:      cfg,
-		userService: service.NewUserService(),
+// Option configures optional handler dependencies
+type Option func(*Handler)
+
+// WithLogger sets the logger used by the handlers and the services they create
+func WithLogger(logger *slog.Logger) Option {
+	return func(h *Handler) {
+		h.logger = logger
+	}
+}
+
+// WithUserService sets the user service instead of creating one from the user repository
+func WithUserService(s *service.UserService) Option {
+	return func(h *Handler) {
+		h.userService = s
+	}
+}
+
+// WithAuthService enables the login, refresh and logout handlers
+func WithAuthService(s *service.AuthService) Option {
+	return func(h *Handler) {
+		h.authService = s
+	}
+}
+
+// WithAPIKeyService enables the API key management handlers
+func WithAPIKeyService(s *service.APIKeyService) Option {
+	return func(h *Handler) {
+		h.apiKeyService = s
+	}
+}
+
+// WithImportService enables the user import and job handlers
+func WithImportService(s *service.ImportService) Option {
+	return func(h *Handler) {
+		h.importService = s
+	}
+}
+
+// WithWebhookService enables the webhook management handlers
+func WithWebhookService(s *service.WebhookService) Option {
+	return func(h *Handler) {
+		h.webhookService = s
+	}
+}
+
+// WithHealth sets the registry whose checks decide readiness
+func WithHealth(registry *health.Registry) Option {
+	return func(h *Handler) {
+		h.health = registry
 	}
 }
 
-// Health returns the health status of the service
-func (h *Handler) Health(c echo.Context) error {
+// New creates a new handler instance using the given user repository for storage
+func New(cfg *config.Config, users repository.UserRepository, opts ...Option) *Handler {
+	h := &Handler{
+		config: cfg,
+		logger: slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.userService == nil {
+		h.userService = service.NewUserService(users, cfg.Pagination.CursorSecret, h.logger)
+	}
+	if h.health == nil {
+		h.health = health.NewRegistry(cfg.Health)
+	}
+	return h
+}
+
+// Liveness reports that the process is running and able to serve requests.
+// It does not check dependencies, so an outage elsewhere doesn't get the
+// server restarted.
+func (h *Handler) Liveness(c echo.Context) error {
+	_, span := startSpan(c, "Handler.Liveness")
+	defer span.End()
+
+	return c.JSON(http.StatusOK, model.HealthResponse{
+		Status:    string(health.StatusOK),
+		Service:   h.config.App.Name,
+		Version:   h.config.App.Version,
+		Timestamp: time.Now(),
+	})
+}
+
+// Readiness reports whether the server should receive traffic, answering 503
+// when a registered check fails or the server is draining
+func (h *Handler) Readiness(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.Readiness")
+	defer span.End()
+
+	report := h.health.Ready(ctx)
+
 	response := model.HealthResponse{
-		Status:    "ok",
+		Status:    string(report.Status),
 		Service:   h.config.App.Name,
 		Version:   h.config.App.Version,
 		Timestamp: time.Now(),
-		Checks: map[string]string{
-			"database": "ok", // In a real app, you'd check database connectivity
-			"memory":   "ok",
-		},
+		Checks:    make(map[string]model.HealthCheck, len(report.Checks)),
+	}
+	for name, result := range report.Checks {
+		response.Checks[name] = model.HealthCheck{
+			Status:    string(result.Status),
+			Error:     result.Error,
+			LatencyMS: float64(result.Duration.Microseconds()) / 1000,
+			CheckedAt: result.CheckedAt,
+		}
 	}
 
-	return c.JSON(http.StatusOK, response)
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, response)
 }
 
 // CreateUser creates a new user
 func (h *Handler) CreateUser(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.CreateUser")
+	defer span.End()
+
 	var req model.CreateUserRequest
 
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: "Invalid request payload",
-		})
+		return errInvalidPayload
 	}
 
-	if err := model.ValidateStruct(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error:   "Validation failed",
-			Details: map[string]interface{}{"validation_errors": model.GetValidationErrors(err)},
-		})
-	}
-
-	user, err := h.userService.CreateUser(&req)
+	user, err := h.userService.CreateUser(ctx, &req)
 	if err != nil {
-		return c.JSON(http.StatusConflict, model.ErrorResponse{
-			Error: err.Error(),
-		})
+		return err
 	}
 
+	setETag(c, user.Version)
 	return c.JSON(http.StatusCreated, user)
 }
 
 // GetUser retrieves a user by ID
 func (h *Handler) GetUser(c echo.Context) error {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+	ctx, span := startSpan(c, "Handler.GetUser")
+	defer span.End()
+
+	id, err := userID(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: "Invalid user ID",
-		})
+		return err
 	}
 
-	user, err := h.userService.GetUser(id)
+	includeDeleted, err := includeDeletedParam(c)
 	if err != nil {
-		return c.JSON(http.StatusNotFound, model.ErrorResponse{
-			Error: err.Error(),
-		})
+		return err
 	}
 
+	user, err := h.userService.GetUser(ctx, id, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	if done, err := notModified(c, user.Version); done {
+		return err
+	}
+
+	setETag(c, user.Version)
 	return c.JSON(http.StatusOK, user)
 }
 
-// UpdateUser updates an existing user
-func (h *Handler) UpdateUser(c echo.Context) error {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+// ReplaceUser replaces every editable field of an existing user
+func (h *Handler) ReplaceUser(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ReplaceUser")
+	defer span.End()
+
+	id, err := userID(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: "Invalid user ID",
-		})
+		return err
 	}
 
-	var req model.UpdateUserRequest
+	var req model.ReplaceUserRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: "Invalid request payload",
-		})
+		return errInvalidPayload
 	}
 
-	if err := model.ValidateStruct(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error:   "Validation failed",
-			Details: map[string]interface{}{"validation_errors": model.GetValidationErrors(err)},
-		})
+	opts, err := updateOptions(c)
+	if err != nil {
+		return err
 	}
 
-	user, err := h.userService.UpdateUser(id, &req)
+	user, err := h.userService.ReplaceUser(ctx, id, &req, opts)
 	if err != nil {
-		status := http.StatusInternalServerError
-		if err.Error() == "user not found" {
-			status = http.StatusNotFound
-		} else if err.Error() == "email already exists" {
-			status = http.StatusConflict
-		}
+		return err
+	}
+
+	setETag(c, user.Version)
+	return c.JSON(http.StatusOK, user)
+}
+
+// PatchUser applies a JSON merge patch or JSON patch to an existing user
+func (h *Handler) PatchUser(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.PatchUser")
+	defer span.End()
+
+	id, err := userID(c)
+	if err != nil {
+		return err
+	}
+
+	format, err := patchFormat(c)
+	if err != nil {
+		return err
+	}
+
+	patch, err := io.ReadAll(io.LimitReader(c.Request().Body, maxPatchSize+1))
+	if err != nil {
+		return errInvalidPayload
+	}
+	if len(patch) > maxPatchSize {
+		return errPatchTooLarge
+	}
 
-		return c.JSON(status, model.ErrorResponse{
-			Error: err.Error(),
-		})
+	opts, err := updateOptions(c)
+	if err != nil {
+		return err
 	}
 
+	user, err := h.userService.PatchUser(ctx, id, format, patch, opts)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, user.Version)
 	return c.JSON(http.StatusOK, user)
 }
 
 // DeleteUser deletes a user by ID
 func (h *Handler) DeleteUser(c echo.Context) error {
-	idParam := c.Param("id")
-	id, err := strconv.Atoi(idParam)
+	ctx, span := startSpan(c, "Handler.DeleteUser")
+	defer span.End()
+
+	id, err := userID(c)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, model.ErrorResponse{
-			Error: "Invalid user ID",
-		})
+		return err
 	}
 
-	if err := h.userService.DeleteUser(id); err != nil {
-		return c.JSON(http.StatusNotFound, model.ErrorResponse{
-			Error: err.Error(),
-		})
+	if err := h.userService.DeleteUser(ctx, id); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
 
+// RestoreUser restores a deleted user
+func (h *Handler) RestoreUser(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.RestoreUser")
+	defer span.End()
+
+	id, err := userID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.userService.RestoreUser(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	setETag(c, user.Version)
+	return c.JSON(http.StatusOK, user)
+}
+
 // ListUsers returns a paginated list of users
 func (h *Handler) ListUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ListUsers")
+	defer span.End()
+
 	// Parse query parameters
 	pageParam := c.QueryParam("page")
 	perPageParam := c.QueryParam("per_page")
 
-	page := 1
-	perPage := 10
+	query := model.UserListQuery{
+		Page:    1,
+		PerPage: 10,
+		Cursor:  c.QueryParam("cursor"),
+	}
 
 	if pageParam != "" {
 		if p, err := strconv.Atoi(pageParam); err == nil && p > 0 {
-			page = p
+			query.Page = p
 		}
 	}
 
 	if perPageParam != "" {
 		if pp, err := strconv.Atoi(perPageParam); err == nil && pp > 0 && pp <= 100 {
-			perPage = pp
+			query.PerPage = pp
 		}
 	}
 
-	response, err := h.userService.ListUsers(page, perPage)
+	if err := parseUserFilters(c, &query); err != nil {
+		return err
+	}
+
+	includeDeleted, err := includeDeletedParam(c)
+	if err != nil {
+		return err
+	}
+	query.IncludeDeleted = includeDeleted
+
+	response, err := h.userService.ListUsers(ctx, &query)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// userID parses the :id path parameter
+func userID(c echo.Context) (int, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, errInvalidUserID
+	}
+	return id, nil
+}
+
+// parseUserFilters reads the filter, search and sort query parameters of ListUsers
+func parseUserFilters(c echo.Context, query *model.UserListQuery) error {
+	fields := make(map[string]string)
+
+	query.Status = splitList(c.QueryParams()["status"])
+	query.Sort = splitList(c.QueryParams()["sort"])
+	query.Search = c.QueryParam("search")
+
+	ints := map[string]**int{
+		"min_age": &query.MinAge,
+		"max_age": &query.MaxAge,
+	}
+	for param, target := range ints {
+		if value := c.QueryParam(param); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				fields[fieldName(param)] = "Must be an integer"
+				continue
+			}
+			*target = &n
+		}
+	}
+
+	times := map[string]**time.Time{
+		"created_after":  &query.CreatedAfter,
+		"created_before": &query.CreatedBefore,
+		"updated_after":  &query.UpdatedAfter,
+		"updated_before": &query.UpdatedBefore,
+	}
+	for param, target := range times {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				fields[fieldName(param)] = "Must be an RFC 3339 timestamp"
+				continue
+			}
+			*target = &t
+		}
+	}
+
+	if len(fields) > 0 {
+		return service.ValidationError(fields)
+	}
+	return nil
+}
+
+// includeDeletedParam parses the include_deleted query parameter, which only
+// callers allowed to read deleted users may set
+func includeDeletedParam(c echo.Context) (bool, error) {
+	value := c.QueryParam("include_deleted")
+	if value == "" {
+		return false, nil
+	}
+
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, service.ValidationError(map[string]string{"IncludeDeleted": "Must be a boolean"})
+	}
+
+	if include && !middleware.Can(c, middleware.ActionReadDeletedUser) {
+		return false, errReadDeletedForbidden
+	}
+	return include, nil
+}
+
+// splitList flattens repeated and comma-separated query parameter values
+func splitList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// fieldName converts a snake_case query parameter to the Go field name used in validation errors
+func fieldName(param string) string {
+	parts := strings.Split(param, "_")
+	for i, part := range parts {
+		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+	}
+	return strings.Join(parts, "")
+}
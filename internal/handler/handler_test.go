@@ -2,19 +2,32 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/health"
+	"github.com/your-org/your-project/internal/middleware"
 	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+	"github.com/your-org/your-project/internal/service"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-func TestHealthHandler(t *testing.T) {
+func TestLivenessHandler(t *testing.T) {
 	// Setup
 	cfg := &config.Config{
 		App: config.AppConfig{
@@ -22,15 +35,15 @@ func TestHealthHandler(t *testing.T) {
 			Version: "1.0.0",
 		},
 	}
-	handler := New(cfg)
+	handler := New(cfg, repository.NewMemoryUserRepository())
 
 	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/health", nil)
+	req := httptest.NewRequest(http.MethodGet, "/livez", nil)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
 	// Test
-	err := handler.Health(c)
+	err := handler.Liveness(c)
 
 	// Assertions
 	assert.NoError(t, err)
@@ -44,6 +57,42 @@ func TestHealthHandler(t *testing.T) {
 	assert.Equal(t, "1.0.0", response.Version)
 }
 
+func TestReadinessHandler(t *testing.T) {
+	// Setup
+	registry := health.NewRegistry(config.HealthConfig{})
+	registry.Register("database", health.CheckerFunc(func(context.Context) error { return nil }))
+	handler := New(&config.Config{}, repository.NewMemoryUserRepository(), WithHealth(registry))
+
+	readiness := func() (int, model.HealthResponse) {
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/readyz", nil), rec)
+		assert.NoError(t, handler.Readiness(c))
+
+		var response model.HealthResponse
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return rec.Code, response
+	}
+
+	// Healthy
+	code, response := readiness()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", response.Status)
+	assert.Equal(t, "ok", response.Checks["database"].Status)
+
+	// Failing check
+	registry.Register("search", health.CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+	code, response = readiness()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "fail", response.Status)
+	assert.Equal(t, "connection refused", response.Checks["search"].Error)
+
+	// Draining
+	registry.SetDraining()
+	code, response = readiness()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "draining", response.Status)
+}
+
 func TestCreateUserHandler(t *testing.T) {
 	// Setup
 	cfg := &config.Config{
@@ -52,7 +101,7 @@ func TestCreateUserHandler(t *testing.T) {
 			Version: "1.0.0",
 		},
 	}
-	handler := New(cfg)
+	handler := New(cfg, repository.NewMemoryUserRepository())
 
 	e := echo.New()
 
@@ -96,7 +145,7 @@ func TestCreateUserHandlerValidationError(t *testing.T) {
 			Version: "1.0.0",
 		},
 	}
-	handler := New(cfg)
+	handler := New(cfg, repository.NewMemoryUserRepository())
 
 	e := echo.New()
 
@@ -118,7 +167,9 @@ func TestCreateUserHandlerValidationError(t *testing.T) {
 	err := handler.CreateUser(c)
 
 	// Assertions
-	assert.NoError(t, err)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	HTTPErrorHandler(err, c)
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	var response model.ErrorResponse
@@ -127,3 +178,635 @@ func TestCreateUserHandlerValidationError(t *testing.T) {
 	assert.Equal(t, "Validation failed", response.Error)
 	assert.NotNil(t, response.Details)
 }
+
+func TestCreateUserHandlerConflict(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Name:    "test-app",
+			Version: "1.0.0",
+		},
+	}
+	handler := New(cfg, repository.NewMemoryUserRepository())
+
+	e := echo.New()
+
+	user := model.CreateUserRequest{
+		Email:     "test@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Age:       25,
+	}
+	jsonData, _ := json.Marshal(user)
+
+	// Test: the second request reuses the same email
+	var rec *httptest.ResponseRecorder
+	var err error
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBuffer(jsonData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec = httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		err = handler.CreateUser(c)
+		if err != nil {
+			HTTPErrorHandler(err, c)
+		}
+	}
+
+	// Assertions
+	assert.ErrorIs(t, err, service.ErrConflict)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestReplaceUserHandlerNotFound(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Name:    "test-app",
+			Version: "1.0.0",
+		},
+	}
+	handler := New(cfg, repository.NewMemoryUserRepository())
+
+	e := echo.New()
+
+	body := `{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","age":30,"status":"active"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/users/42", bytes.NewBufferString(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+
+	// Test
+	err := handler.ReplaceUser(c)
+
+	// Assertions
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	HTTPErrorHandler(err, c)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	var response model.ErrorResponse
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "user with ID 42 not found", response.Error)
+}
+
+func TestProblemDetailsNegotiation(t *testing.T) {
+	// Setup
+	cfg := &config.Config{
+		App: config.AppConfig{
+			Name:    "test-app",
+			Version: "1.0.0",
+		},
+	}
+	handler := New(cfg, repository.NewMemoryUserRepository())
+
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", bytes.NewBufferString(`{"email":"invalid-email"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAccept, "application/problem+json, application/json;q=0.5")
+	rec := httptest.NewRecorder()
+	rec.Header().Set(echo.HeaderXRequestID, "req-123")
+	c := e.NewContext(req, rec)
+
+	// Test
+	err := handler.CreateUser(c)
+	HTTPErrorHandler(err, c)
+
+	// Assertions
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, model.MIMEApplicationProblemJSON, rec.Header().Get(echo.HeaderContentType))
+
+	var problem model.ProblemDetails
+	err = json.Unmarshal(rec.Body.Bytes(), &problem)
+	assert.NoError(t, err)
+	assert.Equal(t, "/problems/validation-error", problem.Type)
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+	assert.Equal(t, "req-123", problem.Instance)
+	assert.Contains(t, problem.Errors, "Email")
+	assert.Contains(t, problem.Errors, "FirstName")
+}
+
+func TestContextErrors(t *testing.T) {
+	cfg := &config.Config{}
+	handler := New(cfg, repository.NewMemoryUserRepository())
+
+	tests := []struct {
+		name       string
+		ctx        func() (context.Context, context.CancelFunc)
+		wantStatus int
+		wantType   string
+	}{
+		{
+			name:       "deadline exceeded",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithTimeout(context.Background(), 0) },
+			wantStatus: http.StatusGatewayTimeout,
+			wantType:   "/problems/timeout",
+		},
+		{
+			name:       "cancelled",
+			ctx:        func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantStatus: http.StatusServiceUnavailable,
+			wantType:   "/problems/unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			cancel()
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil).WithContext(ctx)
+			req.Header.Set(echo.HeaderAccept, model.MIMEApplicationProblemJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := middleware.Timeout(time.Minute)(handler.ListUsers)(c)
+			HTTPErrorHandler(err, c)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+
+			var problem model.ProblemDetails
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &problem))
+			assert.Equal(t, tt.wantType, problem.Type)
+		})
+	}
+}
+
+func TestDeleteAndRestoreUserHandler(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	handler := New(&config.Config{}, repo)
+	require.NoError(t, repo.Create(context.Background(), &model.User{Email: "john@example.com", Status: "active"}))
+
+	e := echo.New()
+	call := func(method, target string, h echo.HandlerFunc, claims *middleware.Claims) (*httptest.ResponseRecorder, error) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(method, target, nil), rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		if claims != nil {
+			policy, err := middleware.ParsePolicy(map[string][]string{"admin": {"users:read_deleted"}, "user": {"users:read"}})
+			require.NoError(t, err)
+			c.Set("policy", policy)
+			c.Set("claims", claims)
+		}
+		return rec, h(c)
+	}
+
+	// Test
+	rec, err := call(http.MethodDelete, "/api/v1/users/1", handler.DeleteUser, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = call(http.MethodGet, "/api/v1/users/1", handler.GetUser, nil)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+
+	_, err = call(http.MethodGet, "/api/v1/users/1?include_deleted=true", handler.GetUser, &middleware.Claims{Role: "user"})
+	assert.Equal(t, errReadDeletedForbidden, err)
+
+	_, err = call(http.MethodGet, "/api/v1/users/1?include_deleted=yes", handler.GetUser, nil)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	rec, err = call(http.MethodGet, "/api/v1/users/1?include_deleted=true", handler.GetUser, &middleware.Claims{Role: "admin"})
+	require.NoError(t, err)
+	assert.Contains(t, rec.Body.String(), `"deleted_at"`)
+
+	rec, err = call(http.MethodPost, "/api/v1/users/1/restore", handler.RestoreUser, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"deleted_at"`)
+
+	_, err = call(http.MethodPost, "/api/v1/users/1/restore", handler.RestoreUser, nil)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
+
+func TestUserETags(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	handler := New(&config.Config{}, repo)
+	require.NoError(t, repo.Create(context.Background(), &model.User{Email: "john@example.com", FirstName: "John", LastName: "Doe", Age: 30, Status: "active"}))
+
+	e := echo.New()
+	call := func(req *http.Request, h echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		return rec, h(c)
+	}
+	update := func(ifMatch string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/1", bytes.NewBufferString(`{"first_name":"Jane"}`))
+		req.Header.Set(echo.HeaderContentType, string(model.MergePatch))
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		return call(req, handler.PatchUser)
+	}
+
+	// Test
+	rec, err := call(httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil), handler.GetUser)
+	require.NoError(t, err)
+	assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set("If-None-Match", `"7", W/"1"`)
+	rec, err = call(req, handler.GetUser)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	_, err = update(`"2"`)
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+
+	_, err = update(`W/"1"`)
+	assert.ErrorIs(t, err, service.ErrPreconditionFailed)
+
+	_, err = update(`"1", "2"`)
+	assert.Equal(t, errInvalidIfMatch, err)
+
+	rec, err = update(`"1"`)
+	require.NoError(t, err)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	rec, err = update("")
+	require.NoError(t, err)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/users/1", nil)
+	req.Header.Set("If-None-Match", `"1"`)
+	rec, err = call(req, handler.GetUser)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	HTTPErrorHandler(service.PreconditionFailedError("stale"), e.NewContext(httptest.NewRequest(http.MethodPut, "/", nil), rec))
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
+
+func TestPatchUserHandler(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	handler := New(&config.Config{}, repo)
+	require.NoError(t, repo.Create(context.Background(), &model.User{
+		Email:     "john@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Age:       30,
+		Phone:     "+1234567890",
+		Status:    "active",
+	}))
+
+	e := echo.New()
+	policy, err := middleware.ParsePolicy(map[string][]string{"admin": {"users:update", "users:update_status"}, "user": {"users:update"}})
+	require.NoError(t, err)
+	patchAs := func(role, contentType, body string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPatch, "/api/v1/users/1", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("1")
+		c.Set("policy", policy)
+		c.Set("claims", &middleware.Claims{Role: role})
+		return rec, handler.PatchUser(c)
+	}
+	patch := func(contentType, body string) (*httptest.ResponseRecorder, error) {
+		return patchAs("admin", contentType, body)
+	}
+
+	// Test
+	rec, err := patch("application/merge-patch+json; charset=utf-8", `{"first_name":"Jane","phone":null}`)
+	require.NoError(t, err)
+	assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+	var user model.User
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+	assert.Equal(t, "Jane", user.FirstName)
+	assert.Equal(t, "Doe", user.LastName)
+	assert.Empty(t, user.Phone)
+
+	rec, err = patch("application/json-patch+json", `[{"op":"replace","path":"/age","value":31}]`)
+	require.NoError(t, err)
+	assert.Equal(t, `"3"`, rec.Header().Get("ETag"))
+
+	_, err = patch("application/json-patch+json", `[{"op":"test","path":"/age","value":30}]`)
+	assert.ErrorIs(t, err, service.ErrConflict)
+
+	_, err = patch("application/merge-patch+json", `{"role":"admin"}`)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	rec, err = patchAs("user", "application/merge-patch+json", `{"status":"suspended"}`)
+	assert.ErrorIs(t, err, service.ErrForbidden)
+	HTTPErrorHandler(err, e.NewContext(httptest.NewRequest(http.MethodPatch, "/", nil), rec))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec, err = patch("application/merge-patch+json", `{"status":"suspended"}`)
+	require.NoError(t, err)
+	assert.Equal(t, `"4"`, rec.Header().Get("ETag"))
+
+	rec, err = patch(echo.MIMEApplicationJSON, `{"first_name":"Jane"}`)
+	assert.Equal(t, errUnsupportedPatch, err)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", rec.Header().Get("Accept-Patch"))
+
+	_, err = patch("application/merge-patch+json", `{"first_name":"`+strings.Repeat("x", maxPatchSize)+`"}`)
+	assert.Equal(t, errPatchTooLarge, err)
+}
+
+func TestBatchUsersHandler(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	handler := New(&config.Config{Users: config.UsersConfig{MaxBatchSize: 3}}, repo)
+	require.NoError(t, repo.Create(context.Background(), &model.User{Email: "john@example.com", FirstName: "John", LastName: "Doe", Age: 30, Status: "active"}))
+
+	e := echo.New()
+	call := func(h echo.HandlerFunc, body string) (model.BatchResponse, error) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users:batch", bytes.NewBufferString(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		if err := h(e.NewContext(req, rec)); err != nil {
+			return model.BatchResponse{}, err
+		}
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response model.BatchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response, nil
+	}
+
+	// Test
+	response, err := call(handler.BatchCreateUsers, `{"users":[
+		{"email":"jane@example.com","first_name":"Jane","last_name":"Doe","age":28},
+		{"email":"john@example.com","first_name":"John","last_name":"Doe","age":30},
+		{"email":"invalid","first_name":"J","last_name":"Doe","age":30}
+	]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 1, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, http.StatusCreated, response.Results[0].Status)
+	assert.Equal(t, 2, response.Results[0].ID)
+	assert.Equal(t, "jane@example.com", response.Results[0].User.Email)
+	assert.Equal(t, http.StatusConflict, response.Results[1].Status)
+	assert.Equal(t, http.StatusBadRequest, response.Results[2].Status)
+	assert.Contains(t, response.Results[2].Error.Errors, "Email")
+	assert.Contains(t, response.Results[2].Error.Errors, "FirstName")
+
+	response, err = call(handler.BatchUpdateUsers, `{"atomic":true,"users":[
+		{"id":1,"first_name":"Johnny"},
+		{"id":2,"version":7,"first_name":"Janet"}
+	]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Failed)
+	assert.Equal(t, http.StatusFailedDependency, response.Results[0].Status)
+	assert.Equal(t, 1, response.Results[0].ID)
+	assert.Equal(t, "/problems/aborted", response.Results[0].Error.Type)
+	assert.Equal(t, http.StatusPreconditionFailed, response.Results[1].Status)
+
+	user, err := repo.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "John", user.FirstName)
+
+	response, err = call(handler.BatchDeleteUsers, `{"ids":[1,2]}`)
+	require.NoError(t, err)
+	assert.Equal(t, 2, response.Succeeded)
+	assert.Equal(t, http.StatusNoContent, response.Results[1].Status)
+	assert.Equal(t, 2, response.Results[1].ID)
+	assert.Nil(t, response.Results[1].User)
+
+	_, err = call(handler.BatchDeleteUsers, `{"ids":[]}`)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	_, err = call(handler.BatchDeleteUsers, `{"ids":[1,2,3,4]}`)
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	_, err = call(handler.BatchDeleteUsers, `{"ids":"1"}`)
+	assert.Equal(t, errInvalidPayload, err)
+}
+
+func TestExportUsersHandler(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	handler := New(&config.Config{}, repo)
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, name := range []string{"Ann", "Bob, Jr.", "=Cat"} {
+		require.NoError(t, repo.Create(context.Background(), &model.User{
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: name,
+			LastName:  "Doe",
+			Age:       20 + i,
+			Status:    "active",
+			Role:      "user",
+			CreatedAt: created,
+			UpdatedAt: created,
+		}))
+	}
+
+	e := echo.New()
+	export := func(target string) (*httptest.ResponseRecorder, error) {
+		rec := httptest.NewRecorder()
+		return rec, handler.ExportUsers(e.NewContext(httptest.NewRequest(http.MethodGet, target, nil), rec))
+	}
+
+	// Test
+	rec, err := export("/api/v1/users/export?min_age=21")
+	require.NoError(t, err)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="users.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "id,email,first_name,last_name,age,phone,status,role,version,created_at,updated_at,deleted_at\n"+
+		"2,user1@example.com,\"Bob, Jr.\",Doe,21,,active,user,1,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z,\n"+
+		"3,user2@example.com,'=Cat,Doe,22,,active,user,1,2024-01-02T03:04:05Z,2024-01-02T03:04:05Z,\n", rec.Body.String())
+
+	rec, err = export("/api/v1/users/export?format=ndjson&sort=-age")
+	require.NoError(t, err)
+	assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	var user model.User
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &user))
+	assert.Equal(t, "=Cat", user.FirstName)
+
+	rec, err = export("/api/v1/users/export?status=suspended")
+	require.NoError(t, err)
+	assert.Equal(t, "id,email,first_name,last_name,age,phone,status,role,version,created_at,updated_at,deleted_at\n", rec.Body.String())
+
+	_, err = export("/api/v1/users/export?format=xml")
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	rec, err = export("/api/v1/users/export?sort=password")
+	assert.ErrorIs(t, err, service.ErrValidation)
+	assert.Empty(t, rec.Body.String())
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	// Setup
+	source := repository.NewMemoryUserRepository()
+	require.NoError(t, source.Create(context.Background(), &model.User{
+		Email: "ann@example.com", FirstName: "Ann", LastName: "Lee", Age: 31, Phone: "+15550100",
+		Status: "active", Role: "user", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}))
+
+	target := repository.NewMemoryUserRepository()
+	users := service.NewUserService(target, "test-secret", slog.New(slog.DiscardHandler))
+	q := queue.New(repository.NewMemoryQueueRepository(), config.QueueConfig{
+		Workers: 1, PollInterval: 10 * time.Millisecond, MaxAttempts: 1, JobTimeout: time.Minute,
+	}, slog.New(slog.DiscardHandler))
+	imports := service.NewImportService(users, repository.NewMemoryJobRepository(), q, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Test
+	rec := httptest.NewRecorder()
+	require.NoError(t, New(&config.Config{}, source).ExportUsers(echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/users/export", nil), rec)))
+	assert.Contains(t, rec.Body.String(), ",+15550100,")
+
+	job, err := imports.StartImport(ctx, model.ImportCSV, rec.Body.Bytes(), false)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err = imports.GetJob(ctx, job.ID)
+		return err == nil && job.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Assertions
+	assert.Equal(t, 1, job.Succeeded, job.Errors)
+	imported, err := target.GetByEmail(ctx, "ann@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "+15550100", imported.Phone)
+}
+
+func TestImportUsersHandler(t *testing.T) {
+	// Setup
+	cfg := &config.Config{Users: config.UsersConfig{Import: config.ImportConfig{MaxSizeMB: 1}}}
+	repo := repository.NewMemoryUserRepository()
+	users := service.NewUserService(repo, "test-secret", slog.New(slog.DiscardHandler))
+	// The queue is not run, so imports stay queued
+	q := queue.New(repository.NewMemoryQueueRepository(), config.QueueConfig{MaxAttempts: 1}, slog.New(slog.DiscardHandler))
+	imports := service.NewImportService(users, repository.NewMemoryJobRepository(), q, slog.New(slog.DiscardHandler))
+	handler := New(cfg, repo, WithUserService(users), WithImportService(imports))
+
+	e := echo.New()
+	upload := func(target, contentType string, body []byte) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, contentType)
+		rec := httptest.NewRecorder()
+		return rec, handler.ImportUsers(e.NewContext(req, rec))
+	}
+	csvFile := []byte("email,first_name,last_name,age\na@example.com,Ann,Lee,31\n")
+
+	// Test
+	rec, err := upload("/api/v1/users/import?dry_run=true", "text/csv", csvFile)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	var job model.Job
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &job))
+	assert.Equal(t, model.JobQueued, job.Status)
+	assert.True(t, job.DryRun)
+	assert.Equal(t, "/api/v1/jobs/"+job.ID, rec.Header().Get(echo.HeaderLocation))
+
+	rec = httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/jobs/"+job.ID, nil), rec)
+	c.SetParamNames("id")
+	c.SetParamValues(job.ID)
+	require.NoError(t, handler.GetJob(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Multipart uploads take their format from the file name
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("file", "users.csv")
+	require.NoError(t, err)
+	_, err = part.Write(csvFile)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	rec, err = upload("/api/v1/users/import", writer.FormDataContentType(), form.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	_, err = upload("/api/v1/users/import", "application/json", []byte(`[]`))
+	assert.Equal(t, errUnsupportedImport, err)
+
+	_, err = upload("/api/v1/users/import?format=ndjson", "application/json", bytes.Repeat([]byte("x"), 1<<20+1))
+	assert.Equal(t, errImportTooLarge, err)
+
+	_, err = upload("/api/v1/users/import", "text/csv", []byte("email,nickname\n"))
+	assert.ErrorIs(t, err, service.ErrValidation)
+
+	_, err = upload("/api/v1/users/import?dry_run=maybe", "text/csv", csvFile)
+	assert.ErrorIs(t, err, service.ErrValidation)
+}
+
+func TestWebhooksHandler(t *testing.T) {
+	// Setup
+	repo := repository.NewMemoryUserRepository()
+	q := queue.New(repository.NewMemoryQueueRepository(), config.QueueConfig{MaxAttempts: 1}, slog.New(slog.DiscardHandler))
+	webhooks := service.NewWebhookService(repository.NewMemoryWebhookRepository(), q, config.WebhooksConfig{MaxAttempts: 1, Timeout: time.Second, AllowPrivateNetworks: true}, slog.New(slog.DiscardHandler))
+	handler := New(&config.Config{}, repo, WithWebhookService(webhooks))
+
+	e := echo.New()
+	call := func(method, target, id, body string, fn echo.HandlerFunc) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		if id != "" {
+			c.SetParamNames("id")
+			c.SetParamValues(id)
+		}
+		return rec, fn(c)
+	}
+
+	// Test
+	rec, err := call(http.MethodPost, "/api/v1/webhooks", "", `{"url":"https://example.com/hooks","events":["user.created"]}`, handler.CreateWebhook)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	var created model.CreatedWebhook
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, strings.HasPrefix(created.Secret, "whsec_"))
+	assert.True(t, created.Active)
+	assert.Equal(t, []model.EventType{model.EventUserCreated}, created.Events)
+
+	// The secret is not returned again
+	rec, err = call(http.MethodGet, "/api/v1/webhooks/"+created.ID, created.ID, "", handler.GetWebhook)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "secret")
+
+	rec, err = call(http.MethodPatch, "/api/v1/webhooks/"+created.ID, created.ID, `{"active":false,"events":[]}`, handler.UpdateWebhook)
+	require.NoError(t, err)
+	var updated model.Webhook
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+	assert.False(t, updated.Active)
+	assert.Empty(t, updated.Events)
+
+	rec, err = call(http.MethodGet, "/api/v1/webhooks/"+created.ID+"/deliveries?limit=10", created.ID, "", handler.ListWebhookDeliveries)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, rec.Body.String())
+
+	_, err = call(http.MethodPost, "/api/v1/webhooks", "", `{"url":"ftp://example.com","events":["user.renamed"]}`, handler.CreateWebhook)
+	var serviceErr *service.Error
+	require.ErrorAs(t, err, &serviceErr)
+	assert.Contains(t, serviceErr.Fields, "URL")
+	assert.Contains(t, serviceErr.Fields, "Events[0]")
+
+	rec, err = call(http.MethodDelete, "/api/v1/webhooks/"+created.ID, created.ID, "", handler.DeleteWebhook)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = call(http.MethodGet, "/api/v1/webhooks/"+created.ID, created.ID, "", handler.GetWebhook)
+	assert.ErrorIs(t, err, service.ErrNotFound)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+)
+
+// importFormMemory is how much of a multipart upload is kept in memory
+// before the rest is spooled to a temporary file
+const importFormMemory = 1 << 20
+
+// importMediaTypes maps the upload media types an import accepts to their format
+var importMediaTypes = map[string]model.ImportFormat{
+	"text/csv":             model.ImportCSV,
+	"application/x-ndjson": model.ImportNDJSON,
+}
+
+// ImportUsers accepts a CSV or NDJSON file of users and queues it for import,
+// answering 202 with the job that reports its progress. The file is sent as
+// the request body or as the "file" part of a multipart form.
+func (h *Handler) ImportUsers(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ImportUsers")
+	defer span.End()
+
+	dryRun := false
+	if value := c.QueryParam("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			return service.ValidationError(map[string]string{"DryRun": "Must be a boolean"})
+		}
+	}
+
+	format, data, err := h.importUpload(c)
+	if err != nil {
+		return err
+	}
+
+	job, err := h.importService.StartImport(ctx, format, data, dryRun)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderLocation, "/api/v1/jobs/"+job.ID)
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// GetJob reports the status and progress of a background job
+func (h *Handler) GetJob(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.GetJob")
+	defer span.End()
+
+	job, err := h.importService.GetJob(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+// importUpload reads the uploaded file and works out its format from the
+// format query parameter, the media type or the uploaded file's extension
+func (h *Handler) importUpload(c echo.Context) (model.ImportFormat, []byte, error) {
+	limit := int64(h.config.Users.Import.MaxSizeMB) << 20
+	format := model.ImportFormat(c.QueryParam("format"))
+
+	mediaType, _, _ := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	var body io.Reader = c.Request().Body
+	if mediaType == echo.MIMEMultipartForm {
+		// Leave room for the form's own boundaries and headers
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, limit+importFormMemory)
+		file, err := c.FormFile("file")
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return "", nil, errImportTooLarge
+			}
+			return "", nil, service.ValidationError(map[string]string{"File": "Must upload a file in the \"file\" form field"})
+		}
+		if format == "" {
+			format = model.ImportFormat(strings.TrimPrefix(path.Ext(file.Filename), "."))
+		}
+
+		src, err := file.Open()
+		if err != nil {
+			return "", nil, err
+		}
+		defer src.Close()
+		body = src
+	} else if format == "" {
+		format = importMediaTypes[mediaType]
+	}
+
+	switch format {
+	case model.ImportCSV, model.ImportNDJSON:
+	default:
+		return "", nil, errUnsupportedImport
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return "", nil, errInvalidPayload
+	}
+	if int64(len(data)) > limit {
+		return "", nil, errImportTooLarge
+	}
+
+	return format, data, nil
+}
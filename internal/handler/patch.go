@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"mime"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/your-org/your-project/internal/middleware"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/service"
+)
+
+// headerAcceptPatch advertises the patch formats PATCH accepts (RFC 5789)
+const headerAcceptPatch = "Accept-Patch"
+
+// maxPatchSize is the largest patch document PATCH reads
+const maxPatchSize = 64 << 10
+
+// acceptPatch lists the supported patch formats for the Accept-Patch header
+var acceptPatch = strings.Join([]string{string(model.MergePatch), string(model.JSONPatch)}, ", ")
+
+// patchFormat returns the patch format named by the request's Content-Type.
+// Unsupported formats are rejected with 415 and an Accept-Patch header.
+func patchFormat(c echo.Context) (model.PatchFormat, error) {
+	mediaType, _, err := mime.ParseMediaType(c.Request().Header.Get(echo.HeaderContentType))
+	if err == nil {
+		switch format := model.PatchFormat(mediaType); format {
+		case model.MergePatch, model.JSONPatch:
+			return format, nil
+		}
+	}
+
+	c.Response().Header().Set(headerAcceptPatch, acceptPatch)
+	return "", errUnsupportedPatch
+}
+
+// updateOptions derives the update options from the If-Match header and the
+// caller's permissions
+func updateOptions(c echo.Context) (service.UpdateOptions, error) {
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return service.UpdateOptions{}, err
+	}
+
+	return service.UpdateOptions{
+		ExpectedVersion:   version,
+		AllowStatusChange: middleware.Can(c, middleware.ActionUpdateUserStatus),
+	}, nil
+}
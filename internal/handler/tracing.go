@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the handler spans
+var tracer = otel.Tracer("github.com/your-org/your-project/internal/handler")
+
+// startSpan starts a span for a handler method as a child of the request span
+func startSpan(c echo.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(c.Request().Context(), name)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/your-org/your-project/internal/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+// defaultDeliveryLimit is how many deliveries ListWebhookDeliveries returns without a limit parameter
+const defaultDeliveryLimit = 50
+
+// CreateWebhook subscribes a URL to user events
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.CreateWebhook")
+	defer span.End()
+
+	var req model.CreateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(ctx, &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+// ListWebhooks returns every webhook
+func (h *Handler) ListWebhooks(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ListWebhooks")
+	defer span.End()
+
+	webhooks, err := h.webhookService.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook returns a webhook by ID
+func (h *Handler) GetWebhook(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.GetWebhook")
+	defer span.End()
+
+	webhook, err := h.webhookService.GetWebhook(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook changes some fields of a webhook
+func (h *Handler) UpdateWebhook(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.UpdateWebhook")
+	defer span.End()
+
+	var req model.UpdateWebhookRequest
+	if err := c.Bind(&req); err != nil {
+		return errInvalidPayload
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(ctx, c.Param("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook removes a webhook
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.DeleteWebhook")
+	defer span.End()
+
+	if err := h.webhookService.DeleteWebhook(ctx, c.Param("id")); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListWebhookDeliveries returns the latest delivery attempts of a webhook
+func (h *Handler) ListWebhookDeliveries(c echo.Context) error {
+	ctx, span := startSpan(c, "Handler.ListWebhookDeliveries")
+	defer span.End()
+
+	limit := defaultDeliveryLimit
+	if l, err := strconv.Atoi(c.QueryParam("limit")); err == nil && l > 0 && l <= 100 {
+		limit = l
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(ctx, c.Param("id"), limit)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
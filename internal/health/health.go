@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+)
+
+// Status is the outcome of a check or of the whole readiness report
+type Status string
+
+// Check and report statuses
+const (
+	StatusOK       Status = "ok"
+	StatusFail     Status = "fail"
+	StatusDraining Status = "draining"
+)
+
+// Checker verifies that a component the server depends on is usable
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(ctx context.Context) error
+
+// Check calls f
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// CheckOption configures a registered check
+type CheckOption func(*check)
+
+// WithTimeout bounds the check with its own timeout instead of the registry default
+func WithTimeout(timeout time.Duration) CheckOption {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// Result is the latest outcome of a single check
+type Result struct {
+	Status    Status
+	Error     string
+	Duration  time.Duration
+	CheckedAt time.Time
+}
+
+// Report is the combined outcome of every registered check
+type Report struct {
+	Status Status
+	Checks map[string]Result
+}
+
+// OK reports whether the server should receive traffic
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Registry holds the checks that decide whether the server is ready for traffic.
+// Check results are cached for the configured TTL so frequent probes don't
+// put load on the dependencies, and concurrent probes share a single run.
+type Registry struct {
+	mutex    sync.RWMutex
+	checks   []*check
+	timeout  time.Duration
+	cacheTTL time.Duration
+	draining atomic.Bool
+}
+
+// NewRegistry creates an empty registry using the configured default timeout and cache TTL
+func NewRegistry(cfg config.HealthConfig) *Registry {
+	return &Registry{
+		timeout:  cfg.Timeout,
+		cacheTTL: cfg.CacheTTL,
+	}
+}
+
+// Register adds a named check to the readiness report
+func (r *Registry) Register(name string, checker Checker, opts ...CheckOption) {
+	c := &check{
+		name:    name,
+		checker: checker,
+		timeout: r.timeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.checks = append(r.checks, c)
+}
+
+// SetDraining marks the server as shutting down. Readiness fails from then on
+// while liveness is unaffected.
+func (r *Registry) SetDraining() {
+	r.draining.Store(true)
+}
+
+// Draining reports whether SetDraining has been called
+func (r *Registry) Draining() bool {
+	return r.draining.Load()
+}
+
+// Ready runs every check whose cached result has expired and reports whether
+// all of them pass. A draining server is never ready.
+func (r *Registry) Ready(ctx context.Context) Report {
+	r.mutex.RLock()
+	checks := make([]*check, len(r.checks))
+	copy(checks, r.checks)
+	r.mutex.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.result(ctx, r.cacheTTL)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(checks))}
+	for i, c := range checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	if r.Draining() {
+		report.Status = StatusDraining
+	}
+
+	return report
+}
+
+// check is a registered checker along with its cached result
+type check struct {
+	name    string
+	checker Checker
+	timeout time.Duration
+
+	mutex  sync.Mutex
+	last   Result
+	cached bool
+}
+
+// result returns the cached result while it is fresh and runs the check otherwise
+func (c *check) result(ctx context.Context, ttl time.Duration) Result {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.cached && time.Since(c.last.CheckedAt) < ttl {
+		return c.last
+	}
+
+	c.last = c.run(ctx)
+	c.cached = true
+	return c.last
+}
+
+// run executes the check. The result is shared with other probes, so it must
+// not fail just because the probe that triggered it went away.
+func (c *check) run(ctx context.Context) Result {
+	ctx = context.WithoutCancel(ctx)
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := safeCheck(ctx, c.checker)
+	result := Result{
+		Status:    StatusOK,
+		Duration:  time.Since(start),
+		CheckedAt: start,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// safeCheck runs a checker, turning a panic into a failure
+func safeCheck(ctx context.Context, checker Checker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("check panicked: %v", r)
+		}
+	}()
+	return checker.Check(ctx)
+}
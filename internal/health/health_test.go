@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-project/internal/config"
+)
+
+func TestReady(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{Timeout: time.Second})
+
+	report := registry.Ready(context.Background())
+	assert.True(t, report.OK())
+	assert.Empty(t, report.Checks)
+
+	registry.Register("ok", CheckerFunc(func(context.Context) error { return nil }))
+	registry.Register("broken", CheckerFunc(func(context.Context) error { return errors.New("down") }))
+
+	report = registry.Ready(context.Background())
+	assert.False(t, report.OK())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+	assert.Equal(t, StatusFail, report.Checks["broken"].Status)
+	assert.Equal(t, "down", report.Checks["broken"].Error)
+}
+
+func TestReadyTimeout(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{Timeout: time.Minute})
+	slow := CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	registry.Register("slow", slow, WithTimeout(10*time.Millisecond))
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusFail, report.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func TestReadyCachesResults(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{CacheTTL: time.Hour})
+
+	var calls atomic.Int32
+	registry.Register("counted", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+
+	first := registry.Ready(context.Background())
+	second := registry.Ready(context.Background())
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, first.Checks["counted"].CheckedAt, second.Checks["counted"].CheckedAt)
+
+	uncached := NewRegistry(config.HealthConfig{})
+	uncached.Register("counted", CheckerFunc(func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+	uncached.Ready(context.Background())
+	uncached.Ready(context.Background())
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestReadyIgnoresProbeCancellation(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{})
+	registry.Register("ctx", CheckerFunc(func(ctx context.Context) error { return ctx.Err() }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.True(t, registry.Ready(ctx).OK())
+}
+
+func TestReadyRecoversPanics(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{})
+	registry.Register("panics", CheckerFunc(func(context.Context) error { panic("boom") }))
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, "check panicked: boom", report.Checks["panics"].Error)
+}
+
+func TestDraining(t *testing.T) {
+	registry := NewRegistry(config.HealthConfig{})
+	registry.Register("ok", CheckerFunc(func(context.Context) error { return nil }))
+	assert.False(t, registry.Draining())
+
+	registry.SetDraining()
+	report := registry.Ready(context.Background())
+	assert.True(t, registry.Draining())
+	assert.Equal(t, StatusDraining, report.Status)
+	assert.Equal(t, StatusOK, report.Checks["ok"].Status)
+}
+
+func TestPingChecker(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+
+	checker := PingChecker(db)
+	assert.NoError(t, checker.Check(context.Background()))
+
+	require.NoError(t, db.Close())
+	assert.Error(t, checker.Check(context.Background()))
+}
+
+func TestHTTPChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, HTTPChecker(server.Client(), server.URL+"/ok").Check(context.Background()))
+	assert.EqualError(t, HTTPChecker(server.Client(), server.URL+"/broken").Check(context.Background()), "unexpected status 500")
+}
+
+func TestDiskSpaceChecker(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, DiskSpaceChecker(dir, 0).Check(context.Background()))
+	assert.Error(t, DiskSpaceChecker(dir, 1<<62).Check(context.Background()))
+}
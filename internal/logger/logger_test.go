@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-project/internal/config"
+)
+
+func TestNewJSON(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(config.LoggerConfig{Level: "warn", Format: "json"}, &buf)
+
+	log.Info("ignored")
+	assert.Empty(t, buf.String())
+
+	ctx := WithAttrs(context.Background(), slog.String("request_id", "req-1"))
+	ctx = WithAttrs(ctx, slog.String("api_key_id", "key-1"))
+	log.WarnContext(ctx, "something happened", "user_id", 7)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "something happened", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "key-1", record["api_key_id"])
+	assert.Equal(t, float64(7), record["user_id"])
+}
+
+func TestNewText(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(config.LoggerConfig{Level: "debug", Format: "text"}, &buf)
+
+	log.With("component", "test").DebugContext(WithAttrs(context.Background(), slog.String("request_id", "req-1")), "hello")
+	assert.Contains(t, buf.String(), "level=DEBUG")
+	assert.Contains(t, buf.String(), "component=test")
+	assert.Contains(t, buf.String(), "request_id=req-1")
+}
+
+func TestParseLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, ParseLevel("DEBUG"))
+	assert.Equal(t, slog.LevelWarn, ParseLevel("warning"))
+	assert.Equal(t, slog.LevelError, ParseLevel("error"))
+	assert.Equal(t, slog.LevelInfo, ParseLevel(""))
+}
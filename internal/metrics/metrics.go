@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// unmatchedRoute labels requests that did not match any registered route, so
+// scans of random URLs cannot create unbounded label values
+const unmatchedRoute = "unmatched"
+
+// countTimeout bounds the user count run on each scrape, staying below
+// Prometheus' default 10s scrape timeout
+const countTimeout = 5 * time.Second
+
+// StatusCounter reports the number of users in each status
+type StatusCounter interface {
+	CountUsersByStatus(ctx context.Context) (map[string]int, error)
+}
+
+// Metrics holds the Prometheus registry and the HTTP request metrics
+type Metrics struct {
+	registry *prometheus.Registry
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+}
+
+// New creates a registry with HTTP request metrics, Go runtime and process
+// metrics, and the users-by-status gauge backed by users
+func New(users StatusCounter) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.duration,
+		m.inFlight,
+		newUsersCollector(users),
+	)
+
+	return m
+}
+
+// Registry returns the registry so other components can register their own metrics
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+}
+
+// Middleware records the rate, errors and duration of every request, labelled
+// by the route template (e.g. /api/v1/users/:id) rather than the raw URL. It
+// writes error responses itself so their status codes are recorded.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			m.inFlight.Inc()
+			defer m.inFlight.Dec()
+
+			if err := next(c); err != nil {
+				c.Error(err)
+			}
+
+			route := c.Path()
+			if route == "" {
+				route = unmatchedRoute
+			}
+
+			method := c.Request().Method
+			m.requests.WithLabelValues(method, route, strconv.Itoa(c.Response().Status)).Inc()
+			m.duration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+			return nil
+		}
+	}
+}
+
+// usersCollector reports the number of users per status each time metrics are scraped
+type usersCollector struct {
+	users   StatusCounter
+	desc    *prometheus.Desc
+	timeout time.Duration
+}
+
+// newUsersCollector creates the users-by-status collector
+func newUsersCollector(users StatusCounter) *usersCollector {
+	return &usersCollector{
+		users:   users,
+		desc:    prometheus.NewDesc("users", "Number of users by status.", []string{"status"}, nil),
+		timeout: countTimeout,
+	}
+}
+
+// Describe sends the descriptor of the users gauge
+func (c *usersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect counts the users in each status. A count that fails or takes
+// longer than the timeout is reported as an invalid metric.
+func (c *usersCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	counts, err := c.users.CountUsersByStatus(ctx)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.desc, err)
+		return
+	}
+
+	for status, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(count), status)
+	}
+}
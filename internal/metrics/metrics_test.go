@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticCounter is a StatusCounter with fixed counts
+type staticCounter map[string]int
+
+func (s staticCounter) CountUsersByStatus(context.Context) (map[string]int, error) {
+	return s, nil
+}
+
+// blockingCounter is a StatusCounter that only returns once ctx is done
+type blockingCounter struct{}
+
+func (blockingCounter) CountUsersByStatus(ctx context.Context) (map[string]int, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestUsersCollectorTimesOut(t *testing.T) {
+	c := newUsersCollector(blockingCounter{})
+	c.timeout = 10 * time.Millisecond
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(c))
+
+	_, err := registry.Gather()
+	assert.ErrorContains(t, err, context.DeadlineExceeded.Error())
+}
+
+func TestMiddlewareLabelsByRoute(t *testing.T) {
+	m := New(staticCounter{"active": 3})
+
+	e := echo.New()
+	e.Use(m.Middleware())
+	e.GET("/users/:id", func(c echo.Context) error {
+		if c.Param("id") == "0" {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/metrics", m.Handler())
+
+	for _, path := range []string{"/users/1", "/users/2", "/users/0", "/no-such-route"} {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("GET", "/users/:id", "404")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("GET", unmatchedRoute, "404")))
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `users{status="active"} 3`)
+	assert.Contains(t, rec.Body.String(), "go_goroutines")
+	assert.Contains(t, rec.Body.String(), `http_request_duration_seconds_count{method="GET",route="/users/:id"} 3`)
+}
@@ -0,0 +1,264 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/logger"
+	"github.com/your-org/your-project/internal/model"
+)
+
+// Claims holds the verified claims of a bearer token, or the identity of an API key
+type Claims struct {
+	// Role selects the caller's permissions in the authorization policy
+	Role string `json:"role,omitempty"`
+	// APIKeyID is set when the caller authenticated with an API key
+	APIKeyID string `json:"-"`
+	// Scopes lists the actions granted to an API key in place of a role
+	Scopes []string `json:"-"`
+	jwt.RegisteredClaims
+}
+
+// APIKeyVerifier resolves the value of an X-API-Key header to the key's record.
+// Errors for unknown, revoked or expired keys are returned to the client as is.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (*model.APIKey, error)
+}
+
+// HeaderAPIKey is the request header carrying an API key
+const HeaderAPIKey = "X-API-Key"
+
+// JWTAuthenticator verifies HS256 or RS256 bearer tokens
+type JWTAuthenticator struct {
+	parser *jwt.Parser
+	key    jwt.Keyfunc
+}
+
+// NewJWTAuthenticator creates an authenticator from the JWT configuration,
+// loading the verification keys for the configured algorithm
+func NewJWTAuthenticator(cfg config.JWTConfig) (*JWTAuthenticator, error) {
+	var key jwt.Keyfunc
+
+	switch cfg.Algorithm {
+	case "HS256":
+		secret := []byte(cfg.Secret)
+		key = func(*jwt.Token) (interface{}, error) { return secret, nil }
+	case "RS256":
+		keys, err := loadRSAKeys(cfg)
+		if err != nil {
+			return nil, err
+		}
+		key = keys.lookup
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.Algorithm)
+	}
+
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTAuthenticator{
+		parser: jwt.NewParser(options...),
+		key:    key,
+	}, nil
+}
+
+// Verify parses a token and returns its claims if the signature and registered claims are valid
+func (a *JWTAuthenticator) Verify(token string) (*Claims, error) {
+	claims := &Claims{}
+	if _, err := a.parser.ParseWithClaims(token, claims, a.key); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Authenticate middleware requires a valid bearer token and injects its claims
+// into the context. When keys is non-nil, requests carrying an X-API-Key header
+// are authenticated with the key instead and its ID is recorded on the context.
+func Authenticate(authenticator *JWTAuthenticator, keys APIKeyVerifier) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if value := c.Request().Header.Get(HeaderAPIKey); value != "" && keys != nil {
+				key, err := keys.VerifyAPIKey(c.Request().Context(), value)
+				if err != nil {
+					return err
+				}
+
+				c.Set("api_key_id", key.ID)
+				c.SetRequest(c.Request().WithContext(
+					logger.WithAttrs(c.Request().Context(), slog.String("api_key_id", key.ID))))
+				c.Set("claims", &Claims{
+					APIKeyID:         key.ID,
+					Scopes:           key.Scopes,
+					RegisteredClaims: jwt.RegisteredClaims{Subject: "api-key:" + key.ID},
+				})
+				return next(c)
+			}
+
+			token, ok := bearerToken(c.Request())
+			if !ok {
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer`)
+				return echo.NewHTTPError(http.StatusUnauthorized, "Missing bearer token")
+			}
+
+			claims, err := authenticator.Verify(token)
+			if err != nil {
+				message := "Invalid bearer token"
+				if errors.Is(err, jwt.ErrTokenExpired) {
+					message = "Bearer token has expired"
+				}
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate,
+					fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, message))
+				return echo.NewHTTPError(http.StatusUnauthorized, message).SetInternal(err)
+			}
+
+			c.Set("claims", claims)
+			return next(c)
+		}
+	}
+}
+
+// GetClaims retrieves the bearer token claims from the context, or nil for unauthenticated requests
+func GetClaims(c echo.Context) *Claims {
+	claims, _ := c.Get("claims").(*Claims)
+	return claims
+}
+
+// GetAPIKeyID retrieves the ID of the API key that authenticated the request, if any
+func GetAPIKeyID(c echo.Context) string {
+	id, _ := c.Get("api_key_id").(string)
+	return id
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get(echo.HeaderAuthorization), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// rsaKeys holds RS256 verification keys indexed by key ID
+type rsaKeys map[string]*rsa.PublicKey
+
+// lookup selects the key named by the token's kid header. Tokens whose kid
+// is unknown fall back to the PEM public key, and tokens without a kid are
+// accepted when exactly one key is configured.
+func (k rsaKeys) lookup(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if key, ok := k[kid]; ok {
+		return key, nil
+	}
+
+	if key, ok := k[""]; ok {
+		return key, nil
+	}
+
+	if kid == "" && len(k) == 1 {
+		for _, key := range k {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// loadRSAKeys reads the RS256 public key file and JWKS file from the configuration
+func loadRSAKeys(cfg config.JWTConfig) (rsaKeys, error) {
+	keys := make(rsaKeys)
+
+	if cfg.PublicKeyFile != "" {
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT public key: %w", err)
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT public key: %w", err)
+		}
+		keys[""] = key
+	}
+
+	if cfg.JWKSFile != "" {
+		if err := loadJWKS(cfg.JWKSFile, keys); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no RS256 verification keys configured")
+	}
+
+	return keys, nil
+}
+
+// jsonWebKey is the subset of an RFC 7517 JSON Web Key needed for RS256 verification
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	Alg     string `json:"alg"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+}
+
+// loadJWKS adds the RSA signing keys of a local JWKS file to keys
+func loadJWKS(path string, keys rsaKeys) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	for _, jwk := range set.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") || (jwk.Alg != "" && jwk.Alg != "RS256") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return fmt.Errorf("invalid modulus for JWKS key %q: %w", jwk.KeyID, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return fmt.Errorf("invalid exponent for JWKS key %q: %w", jwk.KeyID, err)
+		}
+
+		keys[jwk.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return nil
+}
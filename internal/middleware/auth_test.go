@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+)
+
+const testSecret = "test-secret-that-is-at-least-32-bytes"
+
+// authenticate runs the Authenticate middleware for a request with the given Authorization header
+func authenticate(t *testing.T, authenticator *JWTAuthenticator, authorization string) (*httptest.ResponseRecorder, *Claims, error) {
+	t.Helper()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+	if authorization != "" {
+		req.Header.Set(echo.HeaderAuthorization, authorization)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	var claims *Claims
+	err := Authenticate(authenticator, nil)(func(c echo.Context) error {
+		claims = GetClaims(c)
+		return c.NoContent(http.StatusOK)
+	})(c)
+
+	return rec, claims, err
+}
+
+func signHS256(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestAuthenticateHS256(t *testing.T) {
+	authenticator, err := NewJWTAuthenticator(config.JWTConfig{Algorithm: "HS256", Secret: testSecret})
+	require.NoError(t, err)
+
+	valid := signHS256(t, jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+	rec, claims, err := authenticate(t, authenticator, "Bearer "+valid)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", claims.Subject)
+
+	// Missing token
+	rec, _, err = authenticate(t, authenticator, "")
+	assertUnauthorized(t, err, "Missing bearer token")
+	assert.Equal(t, "Bearer", rec.Header().Get(echo.HeaderWWWAuthenticate))
+
+	// Expired token
+	expired := signHS256(t, jwt.RegisteredClaims{
+		Subject:   "42",
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Hour)),
+	})
+	_, _, err = authenticate(t, authenticator, "Bearer "+expired)
+	assertUnauthorized(t, err, "Bearer token has expired")
+
+	// Token without expiry
+	_, _, err = authenticate(t, authenticator, "Bearer "+signHS256(t, jwt.RegisteredClaims{Subject: "42"}))
+	assertUnauthorized(t, err, "Invalid bearer token")
+
+	// Token signed with another secret
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString([]byte("another-secret-that-is-32-bytes-long"))
+	require.NoError(t, err)
+	_, _, err = authenticate(t, authenticator, "Bearer "+forged)
+	assertUnauthorized(t, err, "Invalid bearer token")
+}
+
+func TestAuthenticateRS256WithJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	authenticator, err := NewJWTAuthenticator(config.JWTConfig{
+		Algorithm: "RS256",
+		JWKSFile:  path,
+		Issuer:    "https://issuer.example.com",
+	})
+	require.NoError(t, err)
+
+	sign := func(kid, issuer string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Subject:   "7",
+			Issuer:    issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	_, claims, err := authenticate(t, authenticator, "Bearer "+sign("key-1", "https://issuer.example.com"))
+	require.NoError(t, err)
+	assert.Equal(t, "7", claims.Subject)
+
+	_, _, err = authenticate(t, authenticator, "Bearer "+sign("key-2", "https://issuer.example.com"))
+	assertUnauthorized(t, err, "Invalid bearer token")
+
+	_, _, err = authenticate(t, authenticator, "Bearer "+sign("key-1", "https://other.example.com"))
+	assertUnauthorized(t, err, "Invalid bearer token")
+
+	// An HS256 token must not be accepted by an RS256 authenticator
+	hs := signHS256(t, jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))})
+	_, _, err = authenticate(t, authenticator, "Bearer "+hs)
+	assertUnauthorized(t, err, "Invalid bearer token")
+}
+
+func assertUnauthorized(t *testing.T, err error, message string) {
+	t.Helper()
+
+	var httpErr *echo.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusUnauthorized, httpErr.Code)
+	assert.Equal(t, message, httpErr.Message)
+}
+
+// stubKeys is an APIKeyVerifier accepting a single key value
+type stubKeys struct {
+	value string
+	key   *model.APIKey
+}
+
+func (s stubKeys) VerifyAPIKey(_ context.Context, value string) (*model.APIKey, error) {
+	if value != s.value {
+		return nil, echo.NewHTTPError(http.StatusUnauthorized, "Invalid API key")
+	}
+	return s.key, nil
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	authenticator, err := NewJWTAuthenticator(config.JWTConfig{Algorithm: "HS256", Secret: testSecret})
+	require.NoError(t, err)
+
+	keys := stubKeys{value: "ak_valid", key: &model.APIKey{ID: "key-1", Scopes: []string{"users:list"}}}
+
+	run := func(header string) (echo.Context, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/users", nil)
+		req.Header.Set(HeaderAPIKey, header)
+		c := e.NewContext(req, httptest.NewRecorder())
+		err := Authenticate(authenticator, keys)(func(c echo.Context) error {
+			return c.NoContent(http.StatusOK)
+		})(c)
+		return c, err
+	}
+
+	c, err := run("ak_valid")
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", GetAPIKeyID(c))
+	assert.Equal(t, []string{"users:list"}, GetClaims(c).Scopes)
+
+	_, err = run("ak_invalid")
+	assertUnauthorized(t, err, "Invalid API key")
+}
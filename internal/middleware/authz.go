@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Action identifies an operation guarded by the authorization policy
+type Action string
+
+// Actions on the /users routes
+const (
+	ActionListUsers        Action = "users:list"
+	ActionCreateUser       Action = "users:create"
+	ActionReadUser         Action = "users:read"
+	ActionUpdateUser       Action = "users:update"
+	ActionUpdateUserStatus Action = "users:update_status"
+	ActionDeleteUser       Action = "users:delete"
+	ActionReadDeletedUser  Action = "users:read_deleted"
+	ActionRestoreUser      Action = "users:restore"
+	ActionImportUsers      Action = "users:import"
+)
+
+// ActionReadJobs guards reading the progress of background jobs
+const ActionReadJobs Action = "jobs:read"
+
+// ActionManageAPIKeys guards issuing, listing and revoking API keys
+const ActionManageAPIKeys Action = "api_keys:manage"
+
+// ActionManageWebhooks guards managing webhook subscriptions and reading their deliveries
+const ActionManageWebhooks Action = "webhooks:manage"
+
+// knownActions lists every action a policy may grant
+var knownActions = map[Action]bool{
+	ActionListUsers:        true,
+	ActionCreateUser:       true,
+	ActionReadUser:         true,
+	ActionUpdateUser:       true,
+	ActionUpdateUserStatus: true,
+	ActionDeleteUser:       true,
+	ActionReadDeletedUser:  true,
+	ActionRestoreUser:      true,
+	ActionImportUsers:      true,
+	ActionReadJobs:         true,
+	ActionManageAPIKeys:    true,
+	ActionManageWebhooks:   true,
+}
+
+// Permission grants an action, optionally restricted to the caller's own user record
+type Permission struct {
+	Action Action
+	// OwnOnly limits the permission to requests whose :id matches the token subject
+	OwnOnly bool
+}
+
+// Policy maps role names to the permissions granted to that role
+type Policy map[string][]Permission
+
+// ParsePolicy builds a policy from role → permission lists. A permission is an
+// action name, suffixed with ":own" to restrict it to the caller's own record,
+// e.g. "users:delete" or "users:read:own".
+func ParsePolicy(roles map[string][]string) (Policy, error) {
+	policy := make(Policy, len(roles))
+	for role, grants := range roles {
+		for _, grant := range grants {
+			action, ownOnly := strings.CutSuffix(grant, ":own")
+			if !knownActions[Action(action)] {
+				return nil, fmt.Errorf("unknown action %q for role %q", action, role)
+			}
+			policy[role] = append(policy[role], Permission{Action: Action(action), OwnOnly: ownOnly})
+		}
+	}
+	return policy, nil
+}
+
+// ValidateScopes checks that every API key scope names a known action. Scopes
+// cannot be restricted with ":own" because API keys do not act as a user.
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !knownActions[Action(scope)] {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// Allows reports whether role may perform action. own tells whether the
+// request targets the caller's own record.
+func (p Policy) Allows(role string, action Action, own bool) bool {
+	for _, permission := range p[role] {
+		if permission.Action == action && (!permission.OwnOnly || own) {
+			return true
+		}
+	}
+	return false
+}
+
+// Authorize middleware rejects requests whose authenticated role is not
+// granted action by the policy. It must run after Authenticate.
+func Authorize(policy Policy, action Action) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("policy", policy)
+
+			if !Can(c, action) {
+				return echo.NewHTTPError(http.StatusForbidden, "You are not allowed to perform this action")
+			}
+			return next(c)
+		}
+	}
+}
+
+// Can reports whether the caller may perform action on the user named by the
+// :id path parameter. Requests that did not pass through Authorize are not
+// subject to a policy and are always allowed. API keys are limited to their scopes.
+func Can(c echo.Context, action Action) bool {
+	policy, ok := c.Get("policy").(Policy)
+	if !ok {
+		return true
+	}
+
+	claims := GetClaims(c)
+	if claims == nil {
+		return false
+	}
+
+	if claims.APIKeyID != "" {
+		return slices.Contains(claims.Scopes, string(action))
+	}
+
+	own := claims.Subject != "" && claims.Subject == c.Param("id")
+	return policy.Allows(claims.Role, action, own)
+}
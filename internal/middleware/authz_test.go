@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	policy, err := ParsePolicy(map[string][]string{
+		"admin": {"users:delete"},
+		"user":  {"users:read:own"},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, policy.Allows("admin", ActionDeleteUser, false))
+	assert.False(t, policy.Allows("admin", ActionReadUser, false))
+	assert.True(t, policy.Allows("user", ActionReadUser, true))
+	assert.False(t, policy.Allows("user", ActionReadUser, false))
+	assert.False(t, policy.Allows("guest", ActionReadUser, true))
+
+	_, err = ParsePolicy(map[string][]string{"user": {"users:fly"}})
+	assert.Error(t, err)
+}
+
+func TestValidateScopes(t *testing.T) {
+	assert.NoError(t, ValidateScopes([]string{"users:list", "api_keys:manage"}))
+	assert.Error(t, ValidateScopes([]string{"users:read:own"}))
+	assert.Error(t, ValidateScopes([]string{"users:fly"}))
+}
+
+func TestAuthorize(t *testing.T) {
+	policy, err := ParsePolicy(map[string][]string{
+		"admin": {"users:read", "users:delete"},
+		"user":  {"users:read:own"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		claims  *Claims
+		action  Action
+		id      string
+		allowed bool
+	}{
+		{"admin reads any user", &Claims{Role: "admin"}, ActionReadUser, "7", true},
+		{"admin deletes any user", &Claims{Role: "admin"}, ActionDeleteUser, "7", true},
+		{"user reads own record", userClaims("7"), ActionReadUser, "7", true},
+		{"user reads another record", userClaims("7"), ActionReadUser, "8", false},
+		{"user deletes own record", userClaims("7"), ActionDeleteUser, "7", false},
+		{"unauthenticated request", nil, ActionReadUser, "7", false},
+		{"API key within scope", apiKeyClaims("users:read"), ActionReadUser, "7", true},
+		{"API key outside scope", apiKeyClaims("users:read"), ActionDeleteUser, "7", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/users/"+tt.id, nil)
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues(tt.id)
+			if tt.claims != nil {
+				c.Set("claims", tt.claims)
+			}
+
+			err := Authorize(policy, tt.action)(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})(c)
+
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+
+			var httpErr *echo.HTTPError
+			require.ErrorAs(t, err, &httpErr)
+			assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		})
+	}
+}
+
+func TestCanWithoutPolicy(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	assert.True(t, Can(c, ActionDeleteUser))
+}
+
+func userClaims(subject string) *Claims {
+	return &Claims{Role: "user", RegisteredClaims: jwt.RegisteredClaims{Subject: subject}}
+}
+
+func apiKeyClaims(scopes ...string) *Claims {
+	return &Claims{APIKeyID: "key-1", Scopes: scopes}
+}
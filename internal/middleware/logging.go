@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/your-org/your-project/internal/logger"
+)
+
+// RequestLogger middleware attaches the request ID to the request context, so
+// every record logged with that context carries it, and logs one line per
+// request. It must run after echo's RequestID middleware.
+func RequestLogger(log *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+
+			req := c.Request()
+			ctx := logger.WithAttrs(req.Context(), slog.String("request_id", c.Response().Header().Get(echo.HeaderXRequestID)))
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			if err != nil {
+				// Write the error response now so its status is logged
+				c.Error(err)
+			}
+
+			status := c.Response().Status
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("route", c.Path()),
+				slog.String("uri", req.RequestURI),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.String("remote_ip", c.RealIP()),
+				slog.Int64("bytes_out", c.Response().Size),
+			}
+			if id := GetAPIKeyID(c); id != "" {
+				attrs = append(attrs, slog.String("api_key_id", id))
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			log.LogAttrs(ctx, level, "request", attrs...)
+
+			return nil
+		}
+	}
+}
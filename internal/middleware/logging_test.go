@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/logger"
+)
+
+func TestRequestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(config.LoggerConfig{Level: "info", Format: "json"}, &buf)
+
+	e := echo.New()
+	e.Use(echomiddleware.RequestID())
+	e.Use(RequestLogger(log))
+	e.GET("/users/:id", func(c echo.Context) error {
+		log.InfoContext(c.Request().Context(), "inside handler")
+		return echo.NewHTTPError(http.StatusNotFound, "missing")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/7", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	requestID := rec.Header().Get(echo.HeaderXRequestID)
+	require.NotEmpty(t, requestID)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var handlerLine, requestLine map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[0], &handlerLine))
+	require.NoError(t, json.Unmarshal(lines[1], &requestLine))
+
+	assert.Equal(t, requestID, handlerLine["request_id"])
+	assert.Equal(t, requestID, requestLine["request_id"])
+	assert.Equal(t, "/users/:id", requestLine["route"])
+	assert.Equal(t, float64(http.StatusNotFound), requestLine["status"])
+	assert.Equal(t, "code=404, message=missing", requestLine["error"])
+}
+
+func TestRoutePath(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(config.LoggerConfig{Level: "info", Format: "json"}, &buf)
+
+	e := echo.New()
+	e.Use(RoutePath())
+	e.Use(RequestLogger(log))
+	e.POST("/users\\:batchCreate", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/users:batchCreate", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var requestLine map[string]interface{}
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &requestLine))
+	assert.Equal(t, "/users:batchCreate", requestLine["route"])
+}
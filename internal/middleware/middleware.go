@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/labstack/echo/v4"
 	"github.com/your-org/your-project/internal/config"
 )
@@ -15,6 +17,20 @@ func Config(cfg *config.Config) echo.MiddlewareFunc {
 	}
 }
 
+// RoutePath middleware removes the escapes from the matched route, so a route
+// registered as "/users\\:batchCreate" is logged, traced and counted as the
+// "/users:batchCreate" it serves. It must run before the middleware reading c.Path().
+func RoutePath() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if path := c.Path(); strings.Contains(path, `\:`) {
+				c.SetPath(strings.ReplaceAll(path, `\:`, ":"))
+			}
+			return next(c)
+		}
+	}
+}
+
 // GetConfig retrieves the config from the context
 func GetConfig(c echo.Context) *config.Config {
 	return c.Get("config").(*config.Config)
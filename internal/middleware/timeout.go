@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
+)
+
+// TimeoutConfig configures the Timeout middleware
+type TimeoutConfig struct {
+	// Skipper leaves matching requests without a deadline, e.g. routes that
+	// apply their own
+	Skipper echomiddleware.Skipper
+	// Timeout is the deadline put on each request; zero only reports cancellation
+	Timeout time.Duration
+}
+
+// Timeout bounds every request with a deadline of timeout on its context.
+// Handlers and the services they call observe the deadline through the
+// context; when it expires, or the client goes away, before a response was
+// written the context error is returned so the error handler can answer with
+// 504 or 503. A zero timeout only reports cancellation.
+func Timeout(timeout time.Duration) echo.MiddlewareFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: timeout})
+}
+
+// TimeoutWithConfig returns a Timeout middleware with the given configuration
+func TimeoutWithConfig(config TimeoutConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = echomiddleware.DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+			if config.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+				defer cancel()
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+
+			err := next(c)
+			if ctxErr := ctx.Err(); ctxErr != nil && !c.Response().Committed {
+				return errors.Join(ctxErr, err)
+			}
+			return err
+		}
+	}
+}
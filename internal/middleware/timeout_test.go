@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		handler echo.HandlerFunc
+		wantErr error
+	}{
+		{
+			name:    "completes in time",
+			timeout: time.Second,
+			handler: func(c echo.Context) error { return c.NoContent(http.StatusNoContent) },
+		},
+		{
+			name:    "deadline exceeded",
+			timeout: 10 * time.Millisecond,
+			handler: func(c echo.Context) error {
+				<-c.Request().Context().Done()
+				return c.Request().Context().Err()
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "handler ignores deadline",
+			timeout: 10 * time.Millisecond,
+			handler: func(c echo.Context) error {
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "disabled",
+			timeout: 0,
+			handler: func(c echo.Context) error {
+				_, ok := c.Request().Context().Deadline()
+				assert.False(t, ok)
+				return nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+			err := Timeout(tt.timeout)(tt.handler)(c)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTimeoutClientCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	err := Timeout(time.Second)(func(c echo.Context) error { return nil })(c)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTimeoutSkipper(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+
+	middleware := TimeoutWithConfig(TimeoutConfig{
+		Skipper: func(echo.Context) bool { return true },
+		Timeout: time.Millisecond,
+	})
+	err := middleware(func(c echo.Context) error {
+		_, ok := c.Request().Context().Deadline()
+		assert.False(t, ok)
+		return nil
+	})(c)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,357 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrationsFS embed.FS
+
+// migrationFile matches migration file names such as 0001_create_users.up.sql
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// advisoryLockID is the Postgres advisory lock key held while migrations are
+// applied or reverted, so concurrent runs wait for each other
+const advisoryLockID = 0x6d6967726174
+
+// querier runs statements on a database, a connection or a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Migration is a single versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status describes whether a migration has been applied
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies and reverts the embedded migrations for a database driver
+type Migrator struct {
+	db         *sql.DB
+	driver     string
+	migrations []Migration
+}
+
+// New creates a migrator for the embedded migrations of the given driver
+func New(db *sql.DB, driver string) (*Migrator, error) {
+	migrations, err := load(migrationsFS, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		driver:     driver,
+		migrations: migrations,
+	}, nil
+}
+
+// load reads and orders the migrations stored in dir
+func load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		matches := migrationFile.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(matches[1])
+		name, direction := matches[2], matches[3]
+
+		contents, err := fs.ReadFile(fsys, dir+"/"+entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %s and %s", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(contents)
+		} else {
+			migration.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations in order and returns the number applied.
+// Migrations applied before a failing one stay applied.
+func (m *Migrator) Up() (applied int, err error) {
+	err = m.locked(func(ctx context.Context, conn *sql.Conn) error {
+		pending, err := m.pending(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range pending {
+			if err := m.apply(ctx, conn, migration, true); err != nil {
+				return err
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts up to n of the most recently applied migrations and returns the number reverted
+func (m *Migrator) Down(n int) (reverted int, err error) {
+	err = m.locked(func(ctx context.Context, conn *sql.Conn) error {
+		statuses, err := m.status(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && reverted < n; i-- {
+			if !statuses[i].Applied {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, statuses[i].Migration, false); err != nil {
+				return err
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// locked runs fn on a single connection while holding the migration lock:
+// a session advisory lock on Postgres, and on SQLite a BEGIN IMMEDIATE
+// transaction, which takes the database write lock and is committed once fn
+// returns. A second migrator waits for the lock and then sees the
+// migrations the first one applied.
+func (m *Migrator) locked(fn func(ctx context.Context, conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if m.driver != "sqlite" {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+		return fn(ctx, conn)
+	}
+
+	if _, err := conn.ExecContext(ctx, `BEGIN IMMEDIATE`); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+
+	// A failed migration was already rolled back to its savepoint, so the ones before it are kept
+	fnErr := fn(ctx, conn)
+	if _, err := conn.ExecContext(ctx, `COMMIT`); err != nil {
+		conn.ExecContext(ctx, `ROLLBACK`)
+		return errors.Join(fnErr, fmt.Errorf("failed to commit migrations: %w", err))
+	}
+	return fnErr
+}
+
+// Pending returns the migrations that have not been applied yet
+func (m *Migrator) Pending() ([]Migration, error) {
+	return m.pending(context.Background(), m.db)
+}
+
+// pending returns the migrations q reports as not applied yet
+func (m *Migrator) pending(ctx context.Context, q querier) ([]Migration, error) {
+	statuses, err := m.status(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, status.Migration)
+		}
+	}
+
+	return pending, nil
+}
+
+// Status reports the state of every known migration, ordered by version
+func (m *Migrator) Status() ([]Status, error) {
+	return m.status(context.Background(), m.db)
+}
+
+// status reports the state of every known migration as recorded in q
+func (m *Migrator) status(ctx context.Context, q querier) ([]Status, error) {
+	if err := m.ensureTable(ctx, q); err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{
+			Migration: migration,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return statuses, nil
+}
+
+// ensureTable creates the schema_migrations tracking table if needed. On
+// Postgres applied_at is a TIMESTAMPTZ; tables created as TIMESTAMP by earlier
+// versions, which stored UTC times, are converted.
+func (m *Migrator) ensureTable(ctx context.Context, q querier) error {
+	appliedAtType := "TIMESTAMPTZ"
+	if m.driver == "sqlite" {
+		appliedAtType = "TIMESTAMP"
+	}
+
+	_, err := q.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    BIGINT       NOT NULL PRIMARY KEY,
+		name       VARCHAR(255) NOT NULL,
+		applied_at `+appliedAtType+` NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	if m.driver == "sqlite" {
+		return nil
+	}
+
+	var dataType string
+	err = q.QueryRowContext(ctx, `SELECT data_type FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'schema_migrations' AND column_name = 'applied_at'`).Scan(&dataType)
+	if err != nil {
+		return fmt.Errorf("failed to inspect schema_migrations table: %w", err)
+	}
+	if dataType == "timestamp without time zone" {
+		_, err = q.ExecContext(ctx, `ALTER TABLE schema_migrations
+			ALTER COLUMN applied_at TYPE TIMESTAMPTZ USING applied_at AT TIME ZONE 'UTC'`)
+		if err != nil {
+			return fmt.Errorf("failed to convert schema_migrations.applied_at: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// apply runs one migration and records it atomically. On Postgres it gets its
+// own transaction; on SQLite, where locked has already begun a transaction,
+// it runs under a savepoint instead.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, up bool) error {
+	if m.driver == "sqlite" {
+		if _, err := conn.ExecContext(ctx, `SAVEPOINT migration`); err != nil {
+			return err
+		}
+		if err := m.run(ctx, conn, migration, up); err != nil {
+			conn.ExecContext(ctx, `ROLLBACK TO migration`)
+			conn.ExecContext(ctx, `RELEASE migration`)
+			return err
+		}
+		_, err := conn.ExecContext(ctx, `RELEASE migration`)
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.run(ctx, tx, migration, up); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// run executes a migration's script and records the change in schema_migrations
+func (m *Migrator) run(ctx context.Context, q querier, migration Migration, up bool) error {
+	script := migration.Down
+	if up {
+		script = migration.Up
+	}
+
+	if _, err := q.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	var err error
+	if up {
+		_, err = q.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+			migration.Version, migration.Name, time.Now().UTC(),
+		)
+	} else {
+		_, err = q.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package migrate
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/database"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMigrator(t *testing.T) *Migrator {
+	t.Helper()
+	return openTestMigrator(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+// openTestMigrator creates a migrator with its own connection pool to the SQLite database at path
+func openTestMigrator(t *testing.T, path string) *Migrator {
+	t.Helper()
+
+	db, err := database.Open(config.DatabaseConfig{
+		Driver:       "sqlite",
+		Database:     path,
+		MaxOpenConns: 1,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := New(db, "sqlite")
+	require.NoError(t, err)
+
+	return migrator
+}
+
+func TestUpDownStatus(t *testing.T) {
+	migrator := newTestMigrator(t)
+
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, len(migrator.migrations))
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations), applied)
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied)
+		assert.False(t, status.AppliedAt.IsZero())
+	}
+
+	// Applying again is a no-op
+	applied, err = migrator.Up()
+	require.NoError(t, err)
+	assert.Zero(t, applied)
+
+	reverted, err := migrator.Down(len(migrator.migrations) + 5)
+	require.NoError(t, err)
+	assert.Equal(t, len(migrator.migrations), reverted)
+
+	pending, err = migrator.Pending()
+	require.NoError(t, err)
+	assert.Len(t, pending, len(migrator.migrations))
+}
+
+func TestConcurrentUpAppliesOnce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	migrators := []*Migrator{openTestMigrator(t, path), openTestMigrator(t, path)}
+
+	// The second run waits for the lock and finds nothing left to apply
+	var wg sync.WaitGroup
+	applied := make([]int, len(migrators))
+	errs := make([]error, len(migrators))
+	for i, migrator := range migrators {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			applied[i], errs[i] = migrator.Up()
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errors.Join(errs...))
+	assert.Equal(t, len(migrators[0].migrations), applied[0]+applied[1])
+	assert.Contains(t, applied, 0)
+}
+
+func TestUpKeepsMigrationsBeforeAFailure(t *testing.T) {
+	migrator := newTestMigrator(t)
+	migrator.migrations = []Migration{
+		{Version: 1, Name: "first", Up: "CREATE TABLE first (id INTEGER)", Down: "DROP TABLE first"},
+		{Version: 2, Name: "broken", Up: "CREATE TABLE broken (id INTEGER); NOT SQL", Down: "DROP TABLE broken"},
+	}
+
+	applied, err := migrator.Up()
+	assert.Error(t, err)
+	assert.Equal(t, 1, applied)
+
+	// The failed migration left nothing behind
+	pending, err := migrator.Pending()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "broken", pending[0].Name)
+	_, err = migrator.db.Exec("SELECT id FROM broken")
+	assert.Error(t, err)
+}
+
+func TestLoadOrdersAndValidatesFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_second.up.sql":   {Data: []byte("SELECT 2")},
+		"m/0002_second.down.sql": {Data: []byte("SELECT -2")},
+		"m/0001_first.up.sql":    {Data: []byte("SELECT 1")},
+		"m/0001_first.down.sql":  {Data: []byte("SELECT -1")},
+	}
+
+	migrations, err := load(fsys, "m")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, 1, migrations[0].Version)
+	assert.Equal(t, "second", migrations[1].Name)
+
+	delete(fsys, "m/0002_second.down.sql")
+	_, err = load(fsys, "m")
+	assert.Error(t, err)
+
+	fsys["m/notes.txt"] = &fstest.MapFile{Data: []byte("hello")}
+	_, err = load(fsys, "m")
+	assert.Error(t, err)
+}
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id         BIGSERIAL PRIMARY KEY,
+    email      VARCHAR(255) NOT NULL UNIQUE,
+    first_name VARCHAR(50)  NOT NULL,
+    last_name  VARCHAR(50)  NOT NULL,
+    age        INTEGER      NOT NULL,
+    phone      VARCHAR(32)  NOT NULL DEFAULT '',
+    status     VARCHAR(16)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL,
+    updated_at TIMESTAMPTZ  NOT NULL
+);
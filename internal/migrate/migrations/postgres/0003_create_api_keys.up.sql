@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    id         VARCHAR(64)  PRIMARY KEY,
+    name       VARCHAR(100) NOT NULL,
+    key_hash   VARCHAR(64)  NOT NULL UNIQUE,
+    scopes     TEXT         NOT NULL,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
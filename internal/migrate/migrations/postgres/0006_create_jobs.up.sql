@@ -0,0 +1,15 @@
+CREATE TABLE jobs (
+    id          VARCHAR(64) PRIMARY KEY,
+    type        VARCHAR(64) NOT NULL,
+    status      VARCHAR(16) NOT NULL,
+    dry_run     BOOLEAN     NOT NULL DEFAULT FALSE,
+    total       INTEGER     NOT NULL DEFAULT 0,
+    processed   INTEGER     NOT NULL DEFAULT 0,
+    succeeded   INTEGER     NOT NULL DEFAULT 0,
+    failed      INTEGER     NOT NULL DEFAULT 0,
+    errors      TEXT        NOT NULL DEFAULT 'null',
+    error       TEXT        NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL,
+    started_at  TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ
+);
@@ -0,0 +1,15 @@
+CREATE TABLE queue_jobs (
+    id           VARCHAR(64) PRIMARY KEY,
+    kind         VARCHAR(64) NOT NULL,
+    payload      BYTEA       NOT NULL,
+    status       VARCHAR(16) NOT NULL,
+    attempts     INTEGER     NOT NULL DEFAULT 0,
+    max_attempts INTEGER     NOT NULL,
+    run_at       TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ,
+    last_error   TEXT        NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX queue_jobs_status_run_at_idx ON queue_jobs (status, run_at);
@@ -0,0 +1,11 @@
+CREATE TABLE outbox_events (
+    seq          BIGSERIAL   PRIMARY KEY,
+    id           VARCHAR(64) NOT NULL UNIQUE,
+    type         VARCHAR(64) NOT NULL,
+    user_id      INTEGER     NOT NULL,
+    occurred_at  TIMESTAMPTZ NOT NULL,
+    data         TEXT        NOT NULL,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (seq) WHERE published_at IS NULL;
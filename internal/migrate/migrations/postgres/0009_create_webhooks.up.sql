@@ -0,0 +1,28 @@
+CREATE TABLE webhooks (
+    id            VARCHAR(64)   PRIMARY KEY,
+    url           VARCHAR(2048) NOT NULL,
+    secret        VARCHAR(256)  NOT NULL,
+    events        TEXT          NOT NULL,
+    active        BOOLEAN       NOT NULL DEFAULT TRUE,
+    failure_count INTEGER       NOT NULL DEFAULT 0,
+    disabled_at   TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ   NOT NULL,
+    updated_at    TIMESTAMPTZ   NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    seq         BIGSERIAL    PRIMARY KEY,
+    id          VARCHAR(64)  NOT NULL UNIQUE,
+    webhook_id  VARCHAR(64)  NOT NULL,
+    event_id    VARCHAR(64)  NOT NULL,
+    event_type  VARCHAR(64)  NOT NULL,
+    attempt     INTEGER      NOT NULL,
+    success     BOOLEAN      NOT NULL,
+    status_code INTEGER      NOT NULL DEFAULT 0,
+    error       TEXT         NOT NULL DEFAULT '',
+    duration_ms BIGINT       NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, seq);
+CREATE INDEX webhook_deliveries_created_at_idx ON webhook_deliveries (created_at);
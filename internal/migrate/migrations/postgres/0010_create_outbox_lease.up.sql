@@ -0,0 +1,7 @@
+CREATE TABLE outbox_lease (
+    id           INTEGER     PRIMARY KEY,
+    holder       VARCHAR(64) NOT NULL,
+    locked_until TIMESTAMPTZ
+);
+
+INSERT INTO outbox_lease (id, holder) VALUES (1, '');
@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    email      TEXT     NOT NULL UNIQUE,
+    first_name TEXT     NOT NULL,
+    last_name  TEXT     NOT NULL,
+    age        INTEGER  NOT NULL,
+    phone      TEXT     NOT NULL DEFAULT '',
+    status     TEXT     NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user';
+
+CREATE TABLE refresh_tokens (
+    id         TEXT     PRIMARY KEY,
+    user_id    INTEGER  NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    family_id  TEXT     NOT NULL,
+    token_hash TEXT     NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
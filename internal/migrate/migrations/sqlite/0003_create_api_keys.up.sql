@@ -0,0 +1,9 @@
+CREATE TABLE api_keys (
+    id         TEXT     PRIMARY KEY,
+    name       TEXT     NOT NULL,
+    key_hash   TEXT     NOT NULL UNIQUE,
+    scopes     TEXT     NOT NULL,
+    expires_at DATETIME,
+    created_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
@@ -0,0 +1,3 @@
+DROP INDEX users_deleted_at_idx;
+
+ALTER TABLE users DROP COLUMN deleted_at;
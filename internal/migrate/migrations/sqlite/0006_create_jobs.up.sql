@@ -0,0 +1,15 @@
+CREATE TABLE jobs (
+    id          TEXT     PRIMARY KEY,
+    type        TEXT     NOT NULL,
+    status      TEXT     NOT NULL,
+    dry_run     BOOLEAN  NOT NULL DEFAULT FALSE,
+    total       INTEGER  NOT NULL DEFAULT 0,
+    processed   INTEGER  NOT NULL DEFAULT 0,
+    succeeded   INTEGER  NOT NULL DEFAULT 0,
+    failed      INTEGER  NOT NULL DEFAULT 0,
+    errors      TEXT     NOT NULL DEFAULT 'null',
+    error       TEXT     NOT NULL DEFAULT '',
+    created_at  DATETIME NOT NULL,
+    started_at  DATETIME,
+    finished_at DATETIME
+);
@@ -0,0 +1,15 @@
+CREATE TABLE queue_jobs (
+    id           TEXT     PRIMARY KEY,
+    kind         TEXT     NOT NULL,
+    payload      BLOB     NOT NULL,
+    status       TEXT     NOT NULL,
+    attempts     INTEGER  NOT NULL DEFAULT 0,
+    max_attempts INTEGER  NOT NULL,
+    run_at       DATETIME NOT NULL,
+    locked_until DATETIME,
+    last_error   TEXT     NOT NULL DEFAULT '',
+    created_at   DATETIME NOT NULL,
+    updated_at   DATETIME NOT NULL
+);
+
+CREATE INDEX queue_jobs_status_run_at_idx ON queue_jobs (status, run_at);
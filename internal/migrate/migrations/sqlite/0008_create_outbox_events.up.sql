@@ -0,0 +1,11 @@
+CREATE TABLE outbox_events (
+    seq          INTEGER  PRIMARY KEY AUTOINCREMENT,
+    id           TEXT     NOT NULL UNIQUE,
+    type         TEXT     NOT NULL,
+    user_id      INTEGER  NOT NULL,
+    occurred_at  DATETIME NOT NULL,
+    data         TEXT     NOT NULL,
+    published_at DATETIME
+);
+
+CREATE INDEX outbox_events_pending_idx ON outbox_events (seq) WHERE published_at IS NULL;
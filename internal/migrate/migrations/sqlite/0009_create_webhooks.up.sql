@@ -0,0 +1,28 @@
+CREATE TABLE webhooks (
+    id            TEXT     PRIMARY KEY,
+    url           TEXT     NOT NULL,
+    secret        TEXT     NOT NULL,
+    events        TEXT     NOT NULL,
+    active        BOOLEAN  NOT NULL DEFAULT TRUE,
+    failure_count INTEGER  NOT NULL DEFAULT 0,
+    disabled_at   DATETIME,
+    created_at    DATETIME NOT NULL,
+    updated_at    DATETIME NOT NULL
+);
+
+CREATE TABLE webhook_deliveries (
+    seq         INTEGER  PRIMARY KEY AUTOINCREMENT,
+    id          TEXT     NOT NULL UNIQUE,
+    webhook_id  TEXT     NOT NULL,
+    event_id    TEXT     NOT NULL,
+    event_type  TEXT     NOT NULL,
+    attempt     INTEGER  NOT NULL,
+    success     BOOLEAN  NOT NULL,
+    status_code INTEGER  NOT NULL DEFAULT 0,
+    error       TEXT     NOT NULL DEFAULT '',
+    duration_ms INTEGER  NOT NULL DEFAULT 0,
+    created_at  DATETIME NOT NULL
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, seq);
+CREATE INDEX webhook_deliveries_created_at_idx ON webhook_deliveries (created_at);
@@ -0,0 +1,7 @@
+CREATE TABLE outbox_lease (
+    id           INTEGER  PRIMARY KEY,
+    holder       TEXT     NOT NULL,
+    locked_until DATETIME
+);
+
+INSERT INTO outbox_lease (id, holder) VALUES (1, '');
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// APIKey represents an API key issued to a service caller. Only a hash of the
+// key is stored; the key itself is returned once, when it is created.
+type APIKey struct {
+	ID        string     `json:"id" example:"3f9a1c0d7e2b4a6f8c1d2e3f4a5b6c7d"`
+	Name      string     `json:"name" example:"nightly-sync"`
+	KeyHash   string     `json:"-"`
+	Scopes    []string   `json:"scopes" example:"users:list,users:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request payload for issuing an API key
+type CreateAPIKeyRequest struct {
+	Name      string     `json:"name" validate:"required,min=1,max=100" example:"nightly-sync"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1,dive,required" example:"users:list,users:read"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// CreatedAPIKey represents a newly issued API key along with its secret value
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"ak_q3Jx0b7c2P9mWlU4yTzK8aN1sVdE6fHgRkLo5iBnCuM"`
+}
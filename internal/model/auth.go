@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// LoginRequest represents the request payload for signing in with a password
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,email" example:"user@example.com"`
+	Password string `json:"password" validate:"required" example:"correct-horse-battery"`
+}
+
+// RefreshTokenRequest represents the request payload for refreshing or revoking a session
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required" example:"q3Jx0b7c2P9mWlU4yTzK8aN1sVdE6fHgRkLo5iBnCuM"`
+}
+
+// TokenResponse represents a newly issued access and refresh token pair
+type TokenResponse struct {
+	AccessToken  string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType    string `json:"token_type" example:"Bearer"`
+	ExpiresIn    int    `json:"expires_in" example:"900"`
+	RefreshToken string `json:"refresh_token" example:"q3Jx0b7c2P9mWlU4yTzK8aN1sVdE6fHgRkLo5iBnCuM"`
+}
+
+// RefreshToken represents a server-side refresh token record. Tokens issued
+// by rotating one another share a FamilyID, which identifies the login session.
+type RefreshToken struct {
+	ID        string
+	UserID    int
+	FamilyID  string
+	TokenHash string
+	ExpiresAt time.Time
+	CreatedAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,51 @@
+package model
+
+// BatchCreateUsersRequest represents the request payload for creating several users
+type BatchCreateUsersRequest struct {
+	Users []CreateUserRequest `json:"users"`
+	// Atomic creates either every user or none of them
+	Atomic bool `json:"atomic" example:"false"`
+}
+
+// BatchUpdateUser identifies a user and the fields to update in a batch
+type BatchUpdateUser struct {
+	ID int `json:"id" validate:"required,min=1" example:"1"`
+	// Version makes the update conditional on the user still being at this
+	// version, like If-Match; zero disables the check
+	Version int `json:"version,omitempty" validate:"min=0" example:"3"`
+	UpdateUserRequest
+}
+
+// BatchUpdateUsersRequest represents the request payload for updating several users
+type BatchUpdateUsersRequest struct {
+	Users []BatchUpdateUser `json:"users"`
+	// Atomic updates either every user or none of them
+	Atomic bool `json:"atomic" example:"false"`
+}
+
+// BatchDeleteUsersRequest represents the request payload for deleting several users
+type BatchDeleteUsersRequest struct {
+	IDs []int `json:"ids" example:"1,2,3"`
+	// Atomic deletes either every user or none of them
+	Atomic bool `json:"atomic" example:"false"`
+}
+
+// BatchResponse reports the outcome of every item of a batch request
+type BatchResponse struct {
+	Succeeded int `json:"succeeded" example:"2"`
+	Failed    int `json:"failed" example:"1"`
+	// Results holds one entry per item, in request order
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of one item of a batch request
+type BatchResult struct {
+	// Index is the position of the item in the request
+	Index int `json:"index" example:"0"`
+	// Status is the HTTP status the item would have received as a single request
+	Status int   `json:"status" example:"201"`
+	ID     int   `json:"id,omitempty" example:"1"`
+	User   *User `json:"user,omitempty"`
+	// Error describes why the item failed
+	Error *ProblemDetails `json:"error,omitempty"`
+}
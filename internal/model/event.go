@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType names a kind of domain event
+type EventType string
+
+// User lifecycle event types
+const (
+	EventUserCreated EventType = "user.created"
+	EventUserUpdated EventType = "user.updated"
+	EventUserDeleted EventType = "user.deleted"
+)
+
+// Event is a domain event as stored in the outbox and delivered to sinks.
+// Delivery is at least once, so consumers should ignore IDs they have seen.
+type Event struct {
+	ID   string    `json:"id" example:"9b2e4f1a6c8d4e0f8a1b2c3d4e5f6a7b"`
+	Type EventType `json:"type" example:"user.updated"`
+	// UserID is the user the event is about
+	UserID     int       `json:"user_id" example:"1"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Data holds the typed event, e.g. UserUpdated for user.updated
+	Data json.RawMessage `json:"data"`
+}
+
+// DomainEvent is a typed event payload
+type DomainEvent interface {
+	EventType() EventType
+}
+
+// UserCreated is emitted when a user is created
+type UserCreated struct {
+	User *User `json:"user"`
+}
+
+// EventType returns user.created
+func (UserCreated) EventType() EventType { return EventUserCreated }
+
+// UserUpdated is emitted when a user's fields change, including its status
+// and its restoration after being deleted
+type UserUpdated struct {
+	User *User `json:"user"`
+	// Changes maps each changed field, named as in the user's JSON, to its old and new value
+	Changes map[string]FieldChange `json:"changes"`
+}
+
+// EventType returns user.updated
+func (UserUpdated) EventType() EventType { return EventUserUpdated }
+
+// UserDeleted is emitted when a user is deleted. The user can still be restored until it is purged.
+type UserDeleted struct {
+	UserID    int       `json:"user_id"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// EventType returns user.deleted
+func (UserDeleted) EventType() EventType { return EventUserDeleted }
+
+// FieldChange is the value of a field before and after an update
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// HealthResponse represents the health check response
+type HealthResponse struct {
+	Status    string                 `json:"status" example:"ok"`
+	Service   string                 `json:"service" example:"golang-server-template"`
+	Version   string                 `json:"version" example:"1.0.0"`
+	Timestamp time.Time              `json:"timestamp"`
+	Checks    map[string]HealthCheck `json:"checks,omitempty"`
+}
+
+// HealthCheck represents the latest result of a single health check
+type HealthCheck struct {
+	Status    string    `json:"status" example:"ok"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMS float64   `json:"latency_ms" example:"1.25"`
+	CheckedAt time.Time `json:"checked_at"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// JobStatus is the lifecycle state of a background job
+type JobStatus string
+
+// Job statuses
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+)
+
+// JobTypeUserImport identifies user import jobs
+const JobTypeUserImport = "user_import"
+
+// Job reports the progress of work a request started in the background
+type Job struct {
+	ID     string    `json:"id" example:"3f9a1c0d7e2b4a6f8c1d2e3f4a5b6c7d"`
+	Type   string    `json:"type" example:"user_import"`
+	Status JobStatus `json:"status" example:"running"`
+	// DryRun jobs check their input without changing anything
+	DryRun bool `json:"dry_run" example:"false"`
+	// Total is the number of items to process, known once the job has started
+	Total     int `json:"total" example:"1000"`
+	Processed int `json:"processed" example:"250"`
+	Succeeded int `json:"succeeded" example:"248"`
+	Failed    int `json:"failed" example:"2"`
+	// Errors describes the first items that failed
+	Errors []JobItemError `json:"errors,omitempty"`
+	// Error explains why a failed job stopped
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Done reports whether the job has finished
+func (j *Job) Done() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// JobItemError describes an input item a job could not process
+type JobItemError struct {
+	// Line is the line of the input the item starts on
+	Line   int               `json:"line" example:"12"`
+	Error  string            `json:"error" example:"Validation failed"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// ImportFormat is the file format of a user import
+type ImportFormat string
+
+// Supported import formats
+const (
+	ImportCSV    ImportFormat = "csv"
+	ImportNDJSON ImportFormat = "ndjson"
+)
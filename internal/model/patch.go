@@ -0,0 +1,12 @@
+package model
+
+// PatchFormat identifies the format of a PATCH request body by its media type
+type PatchFormat string
+
+// Supported patch formats
+const (
+	// MergePatch is an RFC 7396 JSON merge patch; a null value clears the field
+	MergePatch PatchFormat = "application/merge-patch+json"
+	// JSONPatch is an RFC 6902 list of JSON patch operations
+	JSONPatch PatchFormat = "application/json-patch+json"
+)
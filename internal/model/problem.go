@@ -0,0 +1,14 @@
+package model
+
+// MIMEApplicationProblemJSON is the media type of RFC 7807 problem details
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// ProblemDetails represents an RFC 7807 problem details response
+type ProblemDetails struct {
+	Type     string            `json:"type" example:"/problems/validation-error"`
+	Title    string            `json:"title" example:"Validation failed"`
+	Status   int               `json:"status" example:"400"`
+	Detail   string            `json:"detail,omitempty" example:"The request body contains invalid fields"`
+	Instance string            `json:"instance,omitempty" example:"3dbe8ec5-1f5a-4b44-a6f3-54ffbb4ce4b6"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// QueueJobStatus is the state of a job in the background queue
+type QueueJobStatus string
+
+// Queue job statuses. Jobs that succeed are removed from the queue.
+const (
+	QueueJobPending QueueJobStatus = "pending"
+	QueueJobRunning QueueJobStatus = "running"
+	// QueueJobDead jobs failed for the last time and are kept as dead letters
+	QueueJobDead QueueJobStatus = "dead"
+)
+
+// QueueJob is a unit of background work waiting in, or held by, the job queue
+type QueueJob struct {
+	ID string `json:"id"`
+	// Kind selects the handler that runs the job
+	Kind    string         `json:"kind"`
+	Payload []byte         `json:"payload"`
+	Status  QueueJobStatus `json:"status"`
+	// Attempts counts the runs started so far, including the current one
+	Attempts    int `json:"attempts"`
+	MaxAttempts int `json:"max_attempts"`
+	// RunAt is the earliest time the job may run
+	RunAt time.Time `json:"run_at"`
+	// LockedUntil is when a running job's lease expires and another worker may take it over
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -15,8 +15,15 @@ type User struct {
 	Age       int       `json:"age" validate:"required,min=1,max=150" example:"25"`
 	Phone     string    `json:"phone,omitempty" validate:"omitempty,e164" example:"+1234567890"`
 	Status    string    `json:"status" validate:"required,oneof=active inactive suspended" example:"active"`
+	Role      string    `json:"role" validate:"-" example:"user"`
 	CreatedAt time.Time `json:"created_at" validate:"-"`
 	UpdatedAt time.Time `json:"updated_at" validate:"-"`
+	// Version is incremented on every change and exposed as the user's ETag
+	Version int `json:"version" validate:"-" example:"1"`
+	// DeletedAt is set once the user is deleted; deleted users are purged after the retention period
+	DeletedAt *time.Time `json:"deleted_at,omitempty" validate:"-"`
+	// PasswordHash is the bcrypt hash of the user's password, empty when the user cannot sign in
+	PasswordHash string `json:"-" validate:"-"`
 }
 
 // CreateUserRequest represents the request payload for creating a user
@@ -26,9 +33,22 @@ type CreateUserRequest struct {
 	LastName  string `json:"last_name" validate:"required,min=2,max=50" example:"Doe"`
 	Age       int    `json:"age" validate:"required,min=1,max=150" example:"25"`
 	Phone     string `json:"phone,omitempty" validate:"omitempty,e164" example:"+1234567890"`
+	// Password is optional; users created without one cannot sign in
+	Password string `json:"password,omitempty" validate:"omitempty,min=8,max=72" example:"correct-horse-battery"`
 }
 
-// UpdateUserRequest represents the request payload for updating a user
+// ReplaceUserRequest represents the request payload for replacing every editable field of a user.
+// Omitting Phone clears it.
+type ReplaceUserRequest struct {
+	Email     string `json:"email" validate:"required,email" example:"user@example.com"`
+	FirstName string `json:"first_name" validate:"required,min=2,max=50" example:"John"`
+	LastName  string `json:"last_name" validate:"required,min=2,max=50" example:"Doe"`
+	Age       int    `json:"age" validate:"required,min=1,max=150" example:"25"`
+	Phone     string `json:"phone,omitempty" validate:"omitempty,e164" example:"+1234567890"`
+	Status    string `json:"status" validate:"required,oneof=active inactive suspended" example:"active"`
+}
+
+// UpdateUserRequest represents the request payload for updating some fields of a user
 type UpdateUserRequest struct {
 	Email     *string `json:"email,omitempty" validate:"omitempty,email" example:"user@example.com"`
 	FirstName *string `json:"first_name,omitempty" validate:"omitempty,min=2,max=50" example:"John"`
@@ -44,12 +64,36 @@ type UserListResponse struct {
 	Meta  MetaData `json:"meta"`
 }
 
+// UserListQuery represents the query parameters for listing users.
+// When Cursor is set the list is paginated by cursor and Page is ignored.
+type UserListQuery struct {
+	Page    int
+	PerPage int
+	Cursor  string
+	Status  []string `validate:"dive,oneof=active inactive suspended"`
+	MinAge  *int     `validate:"omitempty,min=1,max=150"`
+	MaxAge  *int     `validate:"omitempty,min=1,max=150"`
+	// CreatedAfter and UpdatedAfter are inclusive, CreatedBefore and UpdatedBefore exclusive
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	// Search is a case-insensitive prefix of the first name, last name or email
+	Search string `validate:"max=100"`
+	// Sort lists field names to order by, each optionally prefixed with "-" for descending order
+	Sort []string
+	// IncludeDeleted also lists deleted users
+	IncludeDeleted bool
+}
+
 // MetaData represents pagination metadata
 type MetaData struct {
-	Page       int `json:"page" example:"1"`
-	PerPage    int `json:"per_page" example:"10"`
-	Total      int `json:"total" example:"100"`
-	TotalPages int `json:"total_pages" example:"10"`
+	Page       int    `json:"page" example:"1"`
+	PerPage    int    `json:"per_page" example:"10"`
+	Total      int    `json:"total" example:"100"`
+	TotalPages int    `json:"total_pages" example:"10"`
+	NextCursor string `json:"next_cursor,omitempty" example:"eyJhIjoxMH0.c2lnbmF0dXJl"`
+	PrevCursor string `json:"prev_cursor,omitempty" example:"eyJiIjoxfQ.c2lnbmF0dXJl"`
 }
 
 // ErrorResponse represents an error response
@@ -58,15 +102,6 @@ type ErrorResponse struct {
 	Details map[string]interface{} `json:"details,omitempty"`
 }
 
-// HealthResponse represents the health check response
-type HealthResponse struct {
-	Status    string            `json:"status" example:"ok"`
-	Service   string            `json:"service" example:"golang-server-template"`
-	Version   string            `json:"version" example:"1.0.0"`
-	Timestamp time.Time         `json:"timestamp"`
-	Checks    map[string]string `json:"checks,omitempty"`
-}
-
 // Validator instance
 var validate *validator.Validate
 
@@ -102,6 +137,8 @@ func GetValidationErrors(err error) map[string]string {
 				errors[field] = "Must be one of: " + param
 			case "e164":
 				errors[field] = "Must be a valid phone number in E164 format"
+			case "http_url":
+				errors[field] = "Must be an absolute http or https URL"
 			default:
 				errors[field] = "Invalid value"
 			}
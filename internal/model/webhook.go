@@ -0,0 +1,75 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// JobTypeWebhookDelivery identifies queued webhook deliveries
+const JobTypeWebhookDelivery = "webhook_delivery"
+
+// Webhook is a subscription that receives user events by HTTP POST. The
+// secret signs every delivery; it is returned once, when the webhook is created.
+type Webhook struct {
+	ID     string `json:"id" example:"3f9a1c0d7e2b4a6f8c1d2e3f4a5b6c7d"`
+	URL    string `json:"url" example:"https://example.com/hooks/users"`
+	Secret string `json:"-"`
+	// Events filters the delivered event types; empty means every type
+	Events []EventType `json:"events" example:"user.created,user.deleted"`
+	Active bool        `json:"active" example:"true"`
+	// FailureCount is the number of failed deliveries since the last successful one
+	FailureCount int `json:"failure_count" example:"0"`
+	// DisabledAt is set when the webhook was disabled after repeated failures
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Subscribes reports whether the webhook is active and receives events of the given type
+func (w *Webhook) Subscribes(eventType EventType) bool {
+	if !w.Active {
+		return false
+	}
+	return len(w.Events) == 0 || slices.Contains(w.Events, eventType)
+}
+
+// CreateWebhookRequest represents the request payload for creating a webhook
+type CreateWebhookRequest struct {
+	URL    string      `json:"url" validate:"required,http_url,max=2048" example:"https://example.com/hooks/users"`
+	Events []EventType `json:"events" validate:"dive,oneof=user.created user.updated user.deleted" example:"user.created,user.deleted"`
+	// Secret signs the deliveries; a random one is generated when it is empty
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+}
+
+// UpdateWebhookRequest represents the request payload for changing some fields of a webhook
+type UpdateWebhookRequest struct {
+	URL *string `json:"url,omitempty" validate:"omitempty,http_url,max=2048" example:"https://example.com/hooks/users"`
+	// Events replaces the event filter when set; an empty list subscribes to every type
+	Events *[]EventType `json:"events,omitempty" validate:"omitempty,dive,oneof=user.created user.updated user.deleted" example:"user.created"`
+	Secret *string      `json:"secret,omitempty" validate:"omitempty,min=16,max=256"`
+	// Active enables or disables deliveries; enabling a webhook clears its failures
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+// CreatedWebhook represents a newly created webhook along with its signing secret
+type CreatedWebhook struct {
+	Webhook
+	Secret string `json:"secret" example:"whsec_q3Jx0b7c2P9mWlU4yTzK8aN1sVdE6fHgRkLo5iBnCuM"`
+}
+
+// WebhookDelivery records one attempt to deliver an event to a webhook
+type WebhookDelivery struct {
+	ID        string    `json:"id" example:"9b2e4f1a6c8d4e0f8a1b2c3d4e5f6a7b"`
+	WebhookID string    `json:"webhook_id" example:"3f9a1c0d7e2b4a6f8c1d2e3f4a5b6c7d"`
+	EventID   string    `json:"event_id" example:"5c1d2e3f4a5b6c7d3f9a1c0d7e2b4a6f"`
+	EventType EventType `json:"event_type" example:"user.created"`
+	// Attempt counts the tries for this event, starting at 1
+	Attempt int  `json:"attempt" example:"1"`
+	Success bool `json:"success" example:"true"`
+	// StatusCode is the response status, or zero when no response was received
+	StatusCode int `json:"status_code,omitempty" example:"200"`
+	// Error describes why the attempt failed
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms" example:"42"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,279 @@
+// Package queue runs background jobs outside the request path. Jobs are kept
+// in a repository.QueueRepository, so with SQL storage they survive restarts
+// and are shared by every server using the database.
+package queue
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// leaseMargin is added to the job timeout when leasing a job, so a job is only
+// taken over once its run has certainly ended
+const leaseMargin = time.Minute
+
+// tracer creates the job spans
+var tracer = otel.Tracer("github.com/your-org/your-project/internal/queue")
+
+// Handler runs one job of the kind it is registered for. A returned error
+// retries the job with backoff, unless it is wrapped with Permanent.
+type Handler func(ctx context.Context, job *model.QueueJob) error
+
+// Queue enqueues jobs and runs them on a fixed number of workers
+type Queue struct {
+	repo     repository.QueueRepository
+	cfg      config.QueueConfig
+	handlers map[string]Handler
+	mutex    sync.RWMutex
+	// wake tells an idle worker that a job was enqueued
+	wake   chan struct{}
+	logger *slog.Logger
+}
+
+// New creates a new queue storing its jobs in repo
+func New(repo repository.QueueRepository, cfg config.QueueConfig, logger *slog.Logger) *Queue {
+	return &Queue{
+		repo:     repo,
+		cfg:      cfg,
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, 1),
+		logger:   logger,
+	}
+}
+
+// Handle registers the handler for jobs of a kind
+func (q *Queue) Handle(kind string, handler Handler) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.handlers[kind] = handler
+}
+
+// EnqueueOption adjusts a job before it is enqueued
+type EnqueueOption func(job *model.QueueJob)
+
+// Delay runs the job no earlier than d from now
+func Delay(d time.Duration) EnqueueOption {
+	return func(job *model.QueueJob) {
+		job.RunAt = job.RunAt.Add(d)
+	}
+}
+
+// MaxAttempts replaces queue.max_attempts for the job
+func MaxAttempts(n int) EnqueueOption {
+	return func(job *model.QueueJob) {
+		job.MaxAttempts = n
+	}
+}
+
+// Enqueue stores a job of the given kind with payload encoded as JSON
+func (q *Queue) Enqueue(ctx context.Context, kind string, payload interface{}, opts ...EnqueueOption) (*model.QueueJob, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encoding %s job payload: %w", kind, err)
+	}
+
+	now := time.Now()
+	job := &model.QueueJob{
+		ID:          newJobID(),
+		Kind:        kind,
+		Payload:     data,
+		Status:      model.QueueJobPending,
+		MaxAttempts: q.cfg.MaxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+
+	if err := q.repo.Enqueue(ctx, job); err != nil {
+		return nil, err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Run works through due jobs until ctx is cancelled. Jobs that are running by
+// then get queue.drain_timeout to finish before they are cancelled and
+// released to run again without counting the attempt.
+func (q *Queue) Run(ctx context.Context) {
+	// Running jobs outlive ctx until the drain timeout
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+
+	var wg sync.WaitGroup
+	for i := 0; i < q.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.work(ctx, jobCtx)
+		}()
+	}
+
+	<-ctx.Done()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(q.cfg.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		q.logger.Warn("cancelling background jobs still running after the drain timeout", "timeout", q.cfg.DrainTimeout)
+		cancelJobs()
+		<-done
+	}
+}
+
+// work claims and runs jobs until ctx is cancelled, waiting for new jobs when none is due
+func (q *Queue) work(ctx, jobCtx context.Context) {
+	for ctx.Err() == nil {
+		job, err := q.repo.Claim(jobCtx, time.Now(), q.cfg.JobTimeout+leaseMargin)
+		switch {
+		case err == nil:
+			q.run(jobCtx, job)
+			continue
+		case !errors.Is(err, repository.ErrNotFound):
+			q.logger.ErrorContext(ctx, "failed to claim background job", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-q.wake:
+		case <-time.After(q.cfg.PollInterval):
+		}
+	}
+}
+
+// run runs a claimed job and records its outcome
+func (q *Queue) run(ctx context.Context, job *model.QueueJob) {
+	ctx, span := tracer.Start(ctx, "queue."+job.Kind, trace.WithAttributes(
+		attribute.String("job.id", job.ID),
+		attribute.String("job.kind", job.Kind),
+		attribute.Int("job.attempt", job.Attempts),
+	))
+	defer span.End()
+
+	err := q.handle(ctx, job)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	// The outcome is stored even when the job was cancelled by shutdown
+	drained := ctx.Err() != nil
+	ctx = context.WithoutCancel(ctx)
+	log := q.logger.With("job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+
+	var permanent *permanentError
+	switch {
+	case err == nil:
+		err = q.repo.Complete(ctx, job.ID)
+	case drained && !errors.As(err, &permanent):
+		// The run was cut short by shutdown rather than failing, so it does not use up an attempt
+		log.WarnContext(ctx, "background job was cancelled by shutdown and will run again", "error", err)
+		err = q.repo.Release(ctx, job.ID, err.Error())
+	case errors.As(err, &permanent) || job.Attempts >= job.MaxAttempts:
+		log.ErrorContext(ctx, "background job failed and was moved to the dead letters", "error", err)
+		err = q.repo.Bury(ctx, job.ID, err.Error())
+	default:
+		delay := q.backoff(job.Attempts)
+		log.WarnContext(ctx, "background job failed and will be retried", "error", err, "retry_in", delay)
+		err = q.repo.Retry(ctx, job.ID, time.Now().Add(delay), err.Error())
+	}
+	if err != nil {
+		log.ErrorContext(ctx, "failed to record background job outcome", "error", err)
+	}
+}
+
+// handle calls the job's handler within the job timeout, turning panics into errors
+func (q *Queue) handle(ctx context.Context, job *model.QueueJob) (err error) {
+	q.mutex.RLock()
+	handler, ok := q.handlers[job.Kind]
+	q.mutex.RUnlock()
+	if !ok {
+		return Permanent(fmt.Errorf("no handler for job kind %q", job.Kind))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.cfg.JobTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, job)
+}
+
+// backoff returns the delay before retrying a job that failed on the given
+// attempt: queue.retry_backoff doubled for every earlier attempt, capped at
+// queue.max_retry_backoff
+func (q *Queue) backoff(attempt int) time.Duration {
+	delay := q.cfg.RetryBackoff
+	for i := 1; i < attempt && delay < q.cfg.MaxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, q.cfg.MaxRetryBackoff)
+}
+
+// ListDead returns up to limit jobs that will not be retried, most recent first
+func (q *Queue) ListDead(ctx context.Context, limit int) ([]*model.QueueJob, error) {
+	return q.repo.ListDead(ctx, limit)
+}
+
+// permanentError marks a job failure that retrying cannot fix
+type permanentError struct {
+	err error
+}
+
+// Permanent wraps err so the failed job goes straight to the dead letters instead of being retried
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Error returns the wrapped error's message
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,208 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(t *testing.T, cfg config.QueueConfig) (*Queue, *repository.MemoryQueueRepository) {
+	t.Helper()
+
+	defaults := config.QueueConfig{
+		Workers:         2,
+		PollInterval:    5 * time.Millisecond,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+		JobTimeout:      time.Minute,
+		DrainTimeout:    time.Second,
+	}
+	if cfg.Workers == 0 {
+		cfg = defaults
+	}
+
+	repo := repository.NewMemoryQueueRepository()
+	return New(repo, cfg, slog.New(slog.DiscardHandler)), repo
+}
+
+// start runs q until the returned function is called or the test ends
+func start(t *testing.T, q *Queue) (stop func()) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	stop = func() {
+		cancel()
+		<-done
+	}
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestQueueRunsAndRetriesJobs(t *testing.T) {
+	q, repo := newTestQueue(t, config.QueueConfig{})
+	ctx := context.Background()
+
+	var mutex sync.Mutex
+	runs := make(map[string]int)
+	q.Handle("flaky", func(ctx context.Context, job *model.QueueJob) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		runs[string(job.Payload)]++
+		if job.Attempts < 2 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+	start(t, q)
+
+	_, err := q.Enqueue(ctx, "flaky", "a")
+	require.NoError(t, err)
+	_, err = q.Enqueue(ctx, "flaky", "b")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return runs[`"a"`] == 2 && runs[`"b"`] == 2
+	}, 5*time.Second, 5*time.Millisecond)
+
+	// Finished jobs leave the queue
+	assert.Eventually(t, func() bool {
+		_, err := repo.Claim(ctx, time.Now().Add(time.Hour), time.Minute)
+		return errors.Is(err, repository.ErrNotFound)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestQueueDeadLetters(t *testing.T) {
+	q, _ := newTestQueue(t, config.QueueConfig{})
+	ctx := context.Background()
+
+	q.Handle("failing", func(ctx context.Context, job *model.QueueJob) error {
+		return errors.New("still broken")
+	})
+	q.Handle("invalid", func(ctx context.Context, job *model.QueueJob) error {
+		return Permanent(errors.New("cannot be done"))
+	})
+	q.Handle("panicking", func(ctx context.Context, job *model.QueueJob) error {
+		panic("oops")
+	})
+	start(t, q)
+
+	jobs := make(map[string]string)
+	for _, kind := range []string{"failing", "invalid", "panicking", "unknown"} {
+		job, err := q.Enqueue(ctx, kind, nil, MaxAttempts(2))
+		require.NoError(t, err)
+		jobs[job.ID] = kind
+	}
+
+	var dead []*model.QueueJob
+	require.Eventually(t, func() bool {
+		var err error
+		dead, err = q.ListDead(ctx, 10)
+		return err == nil && len(dead) == len(jobs)
+	}, 5*time.Second, 5*time.Millisecond)
+
+	for _, job := range dead {
+		switch jobs[job.ID] {
+		case "failing":
+			assert.Equal(t, 2, job.Attempts)
+			assert.Equal(t, "still broken", job.LastError)
+		case "invalid":
+			assert.Equal(t, 1, job.Attempts)
+			assert.Equal(t, "cannot be done", job.LastError)
+		case "panicking":
+			assert.Equal(t, 2, job.Attempts)
+			assert.Contains(t, job.LastError, "oops")
+		case "unknown":
+			assert.Equal(t, 1, job.Attempts)
+			assert.Contains(t, job.LastError, "no handler")
+		}
+	}
+}
+
+func TestQueueDelay(t *testing.T) {
+	q, repo := newTestQueue(t, config.QueueConfig{})
+	ctx := context.Background()
+
+	job, err := q.Enqueue(ctx, "later", nil, Delay(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, job.RunAt.After(time.Now().Add(59*time.Minute)))
+
+	_, err = repo.Claim(ctx, time.Now(), time.Minute)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	claimed, err := repo.Claim(ctx, time.Now().Add(time.Hour), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, claimed.ID)
+}
+
+func TestQueueDrain(t *testing.T) {
+	q, repo := newTestQueue(t, config.QueueConfig{
+		Workers:         1,
+		PollInterval:    5 * time.Millisecond,
+		MaxAttempts:     1,
+		RetryBackoff:    time.Hour,
+		MaxRetryBackoff: time.Hour,
+		JobTimeout:      time.Minute,
+		DrainTimeout:    20 * time.Millisecond,
+	})
+	ctx := context.Background()
+
+	started := make(chan struct{})
+	q.Handle("slow", func(ctx context.Context, job *model.QueueJob) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	stop := start(t, q)
+
+	job, err := q.Enqueue(ctx, "slow", nil)
+	require.NoError(t, err)
+	<-started
+
+	// The running job is cancelled once the drain timeout passes and is due
+	// again right away; its only attempt is not used up, so it is not buried
+	stop()
+	dead, err := repo.ListDead(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, dead)
+
+	claimed, err := repo.Claim(ctx, time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, job.ID, claimed.ID)
+	assert.Equal(t, 1, claimed.Attempts)
+	assert.Equal(t, context.Canceled.Error(), claimed.LastError)
+}
+
+func TestBackoff(t *testing.T) {
+	q, _ := newTestQueue(t, config.QueueConfig{Workers: 1, RetryBackoff: time.Second, MaxRetryBackoff: 10 * time.Second})
+
+	for attempt, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		4:  8 * time.Second,
+		5:  10 * time.Second,
+		40: 10 * time.Second,
+	} {
+		assert.Equal(t, want, q.backoff(attempt), "attempt %d", attempt)
+	}
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// APIKeyRepository defines the storage operations for API keys
+type APIKeyRepository interface {
+	// Create stores a new API key
+	Create(ctx context.Context, key *model.APIKey) error
+	// GetByHash retrieves an API key by the hash of its value
+	GetByHash(ctx context.Context, hash string) (*model.APIKey, error)
+	// List returns every API key, oldest first
+	List(ctx context.Context) ([]model.APIKey, error)
+	// Revoke marks a key as revoked. It returns ErrNotFound when the key does
+	// not exist or was already revoked.
+	Revoke(ctx context.Context, id string, at time.Time) error
+}
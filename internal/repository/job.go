@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// JobRepository defines the storage operations for background job progress
+type JobRepository interface {
+	// Create stores a new job
+	Create(ctx context.Context, job *model.Job) error
+	// Get retrieves a job by ID
+	Get(ctx context.Context, id string) (*model.Job, error)
+	// Update stores the status and progress of an existing job
+	Update(ctx context.Context, job *model.Job) error
+}
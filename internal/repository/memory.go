@@ -0,0 +1,426 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryUserRepository is an in-memory UserRepository implementation. It
+// also keeps the outbox of the events appended to it.
+type MemoryUserRepository struct {
+	users  map[int]*model.User
+	nextID int
+	outbox []outboxEntry
+	// undo holds, inside Atomic, each changed user as it was before the
+	// transaction first changed it, or nil for users the transaction created
+	undo map[int]*model.User
+	// leaseHolder holds the outbox lease until leaseUntil
+	leaseHolder string
+	leaseUntil  time.Time
+	mutex       sync.RWMutex
+}
+
+// NewMemoryUserRepository creates a new in-memory user repository
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:  make(map[int]*model.User),
+		nextID: 1,
+	}
+}
+
+// Create stores a new user and assigns its ID
+func (r *MemoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.emailTaken(user.Email, 0) {
+		return ErrDuplicateEmail
+	}
+
+	user.ID = r.nextID
+	user.Version = 1
+	r.nextID++
+	r.remember(user.ID)
+
+	stored := *user
+	r.users[user.ID] = &stored
+
+	return nil
+}
+
+// Get retrieves a user by ID
+func (r *MemoryUserRepository) Get(ctx context.Context, id int) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	user, exists := r.users[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	found := *user
+	return &found, nil
+}
+
+// GetByEmail retrieves a user by email address
+func (r *MemoryUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, user := range r.users {
+		if user.Email == email {
+			found := *user
+			return &found, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Update persists changes to an existing user
+func (r *MemoryUserRepository) Update(ctx context.Context, user *model.User) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	existing, exists := r.users[user.ID]
+	if !exists || existing.DeletedAt != nil {
+		return ErrNotFound
+	}
+	if existing.Version != user.Version {
+		return ErrVersionConflict
+	}
+
+	if r.emailTaken(user.Email, user.ID) {
+		return ErrDuplicateEmail
+	}
+
+	r.remember(user.ID)
+	user.Version++
+	stored := *user
+	r.users[user.ID] = &stored
+
+	return nil
+}
+
+// Delete marks a user as deleted at the given time
+func (r *MemoryUserRepository) Delete(ctx context.Context, id int, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, exists := r.users[id]
+	if !exists || user.DeletedAt != nil {
+		return ErrNotFound
+	}
+
+	r.remember(id)
+	user.DeletedAt = &at
+	user.Version++
+	return nil
+}
+
+// Restore clears the deletion mark of a deleted user
+func (r *MemoryUserRepository) Restore(ctx context.Context, id int, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	user, exists := r.users[id]
+	if !exists || user.DeletedAt == nil {
+		return ErrNotFound
+	}
+
+	r.remember(id)
+	user.DeletedAt = nil
+	user.UpdatedAt = at
+	user.Version++
+	return nil
+}
+
+// Purge permanently removes the users deleted before the cutoff
+func (r *MemoryUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	purged := 0
+	for id, user := range r.users {
+		if user.DeletedAt != nil && user.DeletedAt.Before(deletedBefore) {
+			r.remember(id)
+			delete(r.users, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// CountByStatus returns the number of users in each status
+func (r *MemoryUserRepository) CountByStatus(ctx context.Context) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	counts := make(map[string]int)
+	for _, user := range r.users {
+		if user.DeletedAt == nil {
+			counts[user.Status]++
+		}
+	}
+
+	return counts, nil
+}
+
+// List returns a window of the users matching the filter along with the total number of matches
+func (r *MemoryUserRepository) List(ctx context.Context, opts ListOptions) ([]model.User, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := opts.sortKeys()
+	matched := r.matching(&opts.Filter, keys)
+	total := len(matched)
+
+	// Resolve the window boundaries
+	start, end := opts.Offset, opts.Offset+opts.Limit
+	switch {
+	case opts.After != nil:
+		start = sort.Search(total, func(i int) bool { return compareUsers(matched[i], opts.After, keys) > 0 })
+		end = start + opts.Limit
+	case opts.Before != nil:
+		end = sort.Search(total, func(i int) bool { return compareUsers(matched[i], opts.Before, keys) >= 0 })
+		start = max(end-opts.Limit, 0)
+	}
+
+	if start >= total {
+		return []model.User{}, total, nil
+	}
+	end = min(end, total)
+
+	users := make([]model.User, 0, end-start)
+	for i := start; i < end; i++ {
+		users = append(users, *matched[i])
+	}
+
+	return users, total, nil
+}
+
+// Stream calls fn for every user matching the filter, in sort order. Users
+// are read a page at a time, each page positioned after the last user of the
+// previous one like a List cursor, so fn runs without holding the lock.
+func (r *MemoryUserRepository) Stream(ctx context.Context, opts ListOptions, fn func(user *model.User) error) error {
+	opts.Limit, opts.Offset = streamPageSize, 0
+	opts.After, opts.Before = nil, nil
+
+	for {
+		users, _, err := r.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+
+		for i := range users {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(&users[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < streamPageSize {
+			return nil
+		}
+		opts.After = &users[len(users)-1]
+	}
+}
+
+// matching returns the users matching the filter in sort order. The caller must hold the lock.
+func (r *MemoryUserRepository) matching(filter *UserFilter, keys []SortField) []*model.User {
+	matched := make([]*model.User, 0, len(r.users))
+	for _, user := range r.users {
+		if matchesFilter(user, filter) {
+			matched = append(matched, user)
+		}
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return compareUsers(matched[i], matched[j], keys) < 0
+	})
+	return matched
+}
+
+// Atomic runs fn against the stored users, recording the previous state of
+// every user it changes, and undoes the changes when fn fails. Other callers
+// are blocked until fn returns. Calls nested in a transaction join it.
+func (r *MemoryUserRepository) Atomic(ctx context.Context, fn func(repo UserRepository) error) error {
+	if r.undo != nil {
+		return fn(r)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	// The transaction shares the stored users, which the lock keeps to itself.
+	// Events it appends only become visible when it succeeds.
+	tx := &MemoryUserRepository{
+		users:  r.users,
+		nextID: r.nextID,
+		outbox: r.outbox,
+		undo:   make(map[int]*model.User),
+	}
+
+	if err := fn(tx); err != nil {
+		for id, user := range tx.undo {
+			if user == nil {
+				delete(r.users, id)
+			} else {
+				r.users[id] = user
+			}
+		}
+		return err
+	}
+
+	r.nextID, r.outbox = tx.nextID, tx.outbox
+	return nil
+}
+
+// remember records the user with the given ID before a transaction first
+// changes it. Callers must hold the mutex.
+func (r *MemoryUserRepository) remember(id int) {
+	if r.undo == nil {
+		return
+	}
+	if _, seen := r.undo[id]; seen {
+		return
+	}
+
+	if user, exists := r.users[id]; exists {
+		saved := *user
+		r.undo[id] = &saved
+	} else {
+		r.undo[id] = nil
+	}
+}
+
+// emailTaken reports whether a user other than excludeID owns the email.
+// Callers must hold the mutex.
+func (r *MemoryUserRepository) emailTaken(email string, excludeID int) bool {
+	for _, user := range r.users {
+		if user.ID != excludeID && user.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter reports whether the user satisfies every condition of the filter
+func matchesFilter(user *model.User, filter *UserFilter) bool {
+	if user.DeletedAt != nil && !filter.IncludeDeleted {
+		return false
+	}
+	if len(filter.Status) > 0 && !slices.Contains(filter.Status, user.Status) {
+		return false
+	}
+	if filter.MinAge != nil && user.Age < *filter.MinAge {
+		return false
+	}
+	if filter.MaxAge != nil && user.Age > *filter.MaxAge {
+		return false
+	}
+	if filter.CreatedAfter != nil && user.CreatedAt.Before(*filter.CreatedAfter) {
+		return false
+	}
+	if filter.CreatedBefore != nil && !user.CreatedAt.Before(*filter.CreatedBefore) {
+		return false
+	}
+	if filter.UpdatedAfter != nil && user.UpdatedAt.Before(*filter.UpdatedAfter) {
+		return false
+	}
+	if filter.UpdatedBefore != nil && !user.UpdatedAt.Before(*filter.UpdatedBefore) {
+		return false
+	}
+	if filter.Search != "" {
+		prefix := strings.ToLower(filter.Search)
+		if !strings.HasPrefix(strings.ToLower(user.FirstName), prefix) &&
+			!strings.HasPrefix(strings.ToLower(user.LastName), prefix) &&
+			!strings.HasPrefix(strings.ToLower(user.Email), prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// compareUsers orders two users by the given sort keys
+func compareUsers(a, b *model.User, keys []SortField) int {
+	for _, key := range keys {
+		var result int
+		switch key.Field {
+		case "id":
+			result = cmp.Compare(a.ID, b.ID)
+		case "email":
+			result = strings.Compare(a.Email, b.Email)
+		case "first_name":
+			result = strings.Compare(a.FirstName, b.FirstName)
+		case "last_name":
+			result = strings.Compare(a.LastName, b.LastName)
+		case "age":
+			result = cmp.Compare(a.Age, b.Age)
+		case "status":
+			result = strings.Compare(a.Status, b.Status)
+		case "created_at":
+			result = a.CreatedAt.Compare(b.CreatedAt)
+		case "updated_at":
+			result = a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+
+		if key.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryAPIKeyRepository is an in-memory APIKeyRepository implementation
+type MemoryAPIKeyRepository struct {
+	keys  []*model.APIKey
+	mutex sync.RWMutex
+}
+
+// NewMemoryAPIKeyRepository creates a new in-memory API key repository
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{}
+}
+
+// Create stores a new API key
+func (r *MemoryAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.keys = append(r.keys, copyAPIKey(key))
+
+	return nil
+}
+
+// GetByHash retrieves an API key by the hash of its value
+func (r *MemoryAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, key := range r.keys {
+		if key.KeyHash == hash {
+			return copyAPIKey(key), nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// List returns every API key, oldest first
+func (r *MemoryAPIKeyRepository) List(ctx context.Context) ([]model.APIKey, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]model.APIKey, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, *copyAPIKey(key))
+	}
+
+	return keys, nil
+}
+
+// Revoke marks a key as revoked
+func (r *MemoryAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, key := range r.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			key.RevokedAt = &at
+			return nil
+		}
+	}
+
+	return ErrNotFound
+}
+
+// copyAPIKey returns a copy of key that shares no memory with the original
+func copyAPIKey(key *model.APIKey) *model.APIKey {
+	copied := *key
+	copied.Scopes = slices.Clone(key.Scopes)
+	return &copied
+}
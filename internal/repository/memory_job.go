@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryJobRepository is an in-memory JobRepository implementation
+type MemoryJobRepository struct {
+	jobs  map[string]*model.Job
+	mutex sync.RWMutex
+}
+
+// NewMemoryJobRepository creates a new in-memory job repository
+func NewMemoryJobRepository() *MemoryJobRepository {
+	return &MemoryJobRepository{
+		jobs: make(map[string]*model.Job),
+	}
+}
+
+// Create stores a new job
+func (r *MemoryJobRepository) Create(ctx context.Context, job *model.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Get retrieves a job by ID
+func (r *MemoryJobRepository) Get(ctx context.Context, id string) (*model.Job, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	return copyJob(job), nil
+}
+
+// Update stores the status and progress of an existing job
+func (r *MemoryJobRepository) Update(ctx context.Context, job *model.Job) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[job.ID]; !exists {
+		return ErrNotFound
+	}
+
+	r.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// copyJob returns a copy of job that shares no memory with it
+func copyJob(job *model.Job) *model.Job {
+	copied := *job
+	copied.Errors = slices.Clone(job.Errors)
+	return &copied
+}
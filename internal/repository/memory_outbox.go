@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// outboxEntry is an event in the in-memory outbox
+type outboxEntry struct {
+	event       *model.Event
+	publishedAt *time.Time
+}
+
+// AppendEvents stores events in the outbox
+func (r *MemoryUserRepository) AppendEvents(ctx context.Context, events ...*model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, event := range events {
+		r.outbox = append(r.outbox, outboxEntry{event: copyEvent(event)})
+	}
+	return nil
+}
+
+// Lease makes holder the only relay publishing from the outbox until now+ttl
+// and reports whether it is
+func (r *MemoryUserRepository) Lease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.leaseHolder != holder && now.Before(r.leaseUntil) {
+		return false, nil
+	}
+	r.leaseHolder = holder
+	r.leaseUntil = now.Add(ttl)
+	return true, nil
+}
+
+// Pending returns up to limit unpublished events in the order they were stored
+func (r *MemoryUserRepository) Pending(ctx context.Context, limit int) ([]*model.Event, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var events []*model.Event
+	for _, entry := range r.outbox {
+		if len(events) == limit {
+			break
+		}
+		if entry.publishedAt == nil {
+			events = append(events, copyEvent(entry.event))
+		}
+	}
+
+	return events, nil
+}
+
+// MarkPublished records that the events with the given IDs were published
+func (r *MemoryUserRepository) MarkPublished(ctx context.Context, ids []string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for i := range r.outbox {
+		if r.outbox[i].publishedAt == nil && slices.Contains(ids, r.outbox[i].event.ID) {
+			r.outbox[i].publishedAt = &at
+		}
+	}
+	return nil
+}
+
+// PurgePublished removes events published before the cutoff and returns how many were removed
+func (r *MemoryUserRepository) PurgePublished(ctx context.Context, publishedBefore time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	kept := r.outbox[:0]
+	for _, entry := range r.outbox {
+		if entry.publishedAt == nil || !entry.publishedAt.Before(publishedBefore) {
+			kept = append(kept, entry)
+		}
+	}
+
+	purged := len(r.outbox) - len(kept)
+	r.outbox = kept
+	return purged, nil
+}
+
+// copyEvent returns a copy of event that shares no memory with it
+func copyEvent(event *model.Event) *model.Event {
+	copied := *event
+	copied.Data = slices.Clone(event.Data)
+	return &copied
+}
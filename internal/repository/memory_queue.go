@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryQueueRepository is an in-memory QueueRepository implementation
+type MemoryQueueRepository struct {
+	jobs  map[string]*model.QueueJob
+	mutex sync.Mutex
+}
+
+// NewMemoryQueueRepository creates a new in-memory queue repository
+func NewMemoryQueueRepository() *MemoryQueueRepository {
+	return &MemoryQueueRepository{
+		jobs: make(map[string]*model.QueueJob),
+	}
+}
+
+// Enqueue stores a new pending job
+func (r *MemoryQueueRepository) Enqueue(ctx context.Context, job *model.QueueJob) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.jobs[job.ID] = copyQueueJob(job)
+	return nil
+}
+
+// Claim leases the due job with the earliest run time
+func (r *MemoryQueueRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.QueueJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var next *model.QueueJob
+	for _, job := range r.jobs {
+		if !queueJobDue(job, now) {
+			continue
+		}
+		if next == nil || job.RunAt.Before(next.RunAt) || job.RunAt.Equal(next.RunAt) && job.ID < next.ID {
+			next = job
+		}
+	}
+	if next == nil {
+		return nil, ErrNotFound
+	}
+
+	lockedUntil := now.Add(lease)
+	next.Status = model.QueueJobRunning
+	next.Attempts++
+	next.LockedUntil = &lockedUntil
+	next.UpdatedAt = now
+	return copyQueueJob(next), nil
+}
+
+// Complete removes a job that finished
+func (r *MemoryQueueRepository) Complete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.jobs[id]; !exists {
+		return ErrNotFound
+	}
+
+	delete(r.jobs, id)
+	return nil
+}
+
+// Retry releases a claimed job to run again at runAt
+func (r *MemoryQueueRepository) Retry(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	return r.release(ctx, id, model.QueueJobPending, runAt, lastError)
+}
+
+// Release returns a claimed job to the due jobs without counting the attempt
+func (r *MemoryQueueRepository) Release(ctx context.Context, id string, lastError string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	job.Status = model.QueueJobPending
+	job.Attempts = max(job.Attempts-1, 0)
+	job.LockedUntil = nil
+	job.LastError = lastError
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+// Bury keeps a job that will not be retried as a dead letter
+func (r *MemoryQueueRepository) Bury(ctx context.Context, id string, lastError string) error {
+	return r.release(ctx, id, model.QueueJobDead, time.Time{}, lastError)
+}
+
+// release ends the lease on a job, moving it to status. A zero runAt keeps the current run time.
+func (r *MemoryQueueRepository) release(ctx context.Context, id string, status model.QueueJobStatus, runAt time.Time, lastError string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return ErrNotFound
+	}
+
+	job.Status = status
+	if !runAt.IsZero() {
+		job.RunAt = runAt
+	}
+	job.LockedUntil = nil
+	job.LastError = lastError
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+// ListDead returns up to limit dead letters, most recently buried first
+func (r *MemoryQueueRepository) ListDead(ctx context.Context, limit int) ([]*model.QueueJob, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var dead []*model.QueueJob
+	for _, job := range r.jobs {
+		if job.Status == model.QueueJobDead {
+			dead = append(dead, copyQueueJob(job))
+		}
+	}
+
+	sort.Slice(dead, func(i, j int) bool {
+		if !dead[i].UpdatedAt.Equal(dead[j].UpdatedAt) {
+			return dead[i].UpdatedAt.After(dead[j].UpdatedAt)
+		}
+		return dead[i].ID < dead[j].ID
+	})
+	if len(dead) > limit {
+		dead = dead[:limit]
+	}
+
+	return dead, nil
+}
+
+// queueJobDue reports whether a job may be claimed at now
+func queueJobDue(job *model.QueueJob, now time.Time) bool {
+	switch job.Status {
+	case model.QueueJobPending:
+		return !job.RunAt.After(now)
+	case model.QueueJobRunning:
+		return job.LockedUntil != nil && !job.LockedUntil.After(now)
+	default:
+		return false
+	}
+}
+
+// copyQueueJob returns a copy of job that shares no memory with it
+func copyQueueJob(job *model.QueueJob) *model.QueueJob {
+	copied := *job
+	copied.Payload = slices.Clone(job.Payload)
+	if job.LockedUntil != nil {
+		lockedUntil := *job.LockedUntil
+		copied.LockedUntil = &lockedUntil
+	}
+	return &copied
+}
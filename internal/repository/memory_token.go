@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryRefreshTokenRepository is an in-memory RefreshTokenRepository implementation
+type MemoryRefreshTokenRepository struct {
+	tokens map[string]*model.RefreshToken
+	mutex  sync.RWMutex
+}
+
+// NewMemoryRefreshTokenRepository creates a new in-memory refresh token repository
+func NewMemoryRefreshTokenRepository() *MemoryRefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{
+		tokens: make(map[string]*model.RefreshToken),
+	}
+}
+
+// Create stores a new refresh token
+func (r *MemoryRefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored := *token
+	r.tokens[token.ID] = &stored
+
+	return nil
+}
+
+// GetByHash retrieves a refresh token by the hash of its value
+func (r *MemoryRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == hash {
+			found := *token
+			return &found, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// Revoke marks a token as revoked
+func (r *MemoryRefreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	token, exists := r.tokens[id]
+	if !exists || token.RevokedAt != nil {
+		return ErrNotFound
+	}
+
+	token.RevokedAt = &at
+	return nil
+}
+
+// RevokeFamily revokes every token of a login session
+func (r *MemoryRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, token := range r.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &at
+		}
+	}
+
+	return nil
+}
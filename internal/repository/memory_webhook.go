@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// MemoryWebhookRepository is an in-memory WebhookRepository implementation
+type MemoryWebhookRepository struct {
+	webhooks   []*model.Webhook
+	deliveries []model.WebhookDelivery
+	mutex      sync.RWMutex
+}
+
+// NewMemoryWebhookRepository creates a new in-memory webhook repository
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{}
+}
+
+// Create stores a new webhook
+func (r *MemoryWebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.webhooks = append(r.webhooks, copyWebhook(webhook))
+	return nil
+}
+
+// Get retrieves a webhook by ID
+func (r *MemoryWebhookRepository) Get(ctx context.Context, id string) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if i := r.index(id); i >= 0 {
+		return copyWebhook(r.webhooks[i]), nil
+	}
+	return nil, ErrNotFound
+}
+
+// List returns every webhook, oldest first
+func (r *MemoryWebhookRepository) List(ctx context.Context) ([]model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	webhooks := make([]model.Webhook, 0, len(r.webhooks))
+	for _, webhook := range r.webhooks {
+		webhooks = append(webhooks, *copyWebhook(webhook))
+	}
+	return webhooks, nil
+}
+
+// Update stores the settings and state of an existing webhook
+func (r *MemoryWebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i := r.index(webhook.ID)
+	if i < 0 {
+		return ErrNotFound
+	}
+	r.webhooks[i] = copyWebhook(webhook)
+	return nil
+}
+
+// Delete removes a webhook and its delivery log
+func (r *MemoryWebhookRepository) Delete(ctx context.Context, id string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i := r.index(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	r.webhooks = slices.Delete(r.webhooks, i, i+1)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(delivery model.WebhookDelivery) bool {
+		return delivery.WebhookID == id
+	})
+	return nil
+}
+
+// RecordDelivery logs a delivery attempt and updates the webhook's failure count
+func (r *MemoryWebhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (*model.Webhook, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	i := r.index(delivery.WebhookID)
+	if i < 0 {
+		return nil, ErrNotFound
+	}
+
+	webhook := r.webhooks[i]
+	if delivery.Success {
+		webhook.FailureCount = 0
+	} else {
+		webhook.FailureCount++
+		if disableAfter > 0 && webhook.FailureCount >= disableAfter && webhook.Active {
+			disabledAt := delivery.CreatedAt
+			webhook.Active = false
+			webhook.DisabledAt = &disabledAt
+			webhook.UpdatedAt = disabledAt
+		}
+	}
+
+	r.deliveries = append(r.deliveries, *delivery)
+	return copyWebhook(webhook), nil
+}
+
+// ListDeliveries returns up to limit delivery attempts of a webhook, newest first
+func (r *MemoryWebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if r.deliveries[i].WebhookID == webhookID {
+			deliveries = append(deliveries, r.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+// PurgeDeliveries removes delivery attempts made before the cutoff
+func (r *MemoryWebhookRepository) PurgeDeliveries(ctx context.Context, before time.Time) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := len(r.deliveries)
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(delivery model.WebhookDelivery) bool {
+		return delivery.CreatedAt.Before(before)
+	})
+	return count - len(r.deliveries), nil
+}
+
+// index returns the position of the webhook with the given ID, or -1. The caller must hold the mutex.
+func (r *MemoryWebhookRepository) index(id string) int {
+	return slices.IndexFunc(r.webhooks, func(webhook *model.Webhook) bool {
+		return webhook.ID == id
+	})
+}
+
+// copyWebhook returns a copy of webhook that shares no memory with the original
+func copyWebhook(webhook *model.Webhook) *model.Webhook {
+	copied := *webhook
+	copied.Events = slices.Clone(webhook.Events)
+	if webhook.DisabledAt != nil {
+		disabledAt := *webhook.DisabledAt
+		copied.DisabledAt = &disabledAt
+	}
+	return &copied
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// OutboxRepository reads the events UserRepository.AppendEvents stored so they
+// can be published
+type OutboxRepository interface {
+	// Lease makes holder the only relay publishing from the outbox until
+	// now+ttl and reports whether it is. The holder may renew its lease at any
+	// time; another holder only gets it once it has expired.
+	Lease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error)
+	// Pending returns up to limit unpublished events in the order they were stored
+	Pending(ctx context.Context, limit int) ([]*model.Event, error)
+	// MarkPublished records that the events with the given IDs were published
+	MarkPublished(ctx context.Context, ids []string, at time.Time) error
+	// PurgePublished removes events published before the cutoff and returns how many were removed
+	PurgePublished(ctx context.Context, publishedBefore time.Time) (int, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// QueueRepository defines the storage operations for the background job queue
+type QueueRepository interface {
+	// Enqueue stores a new pending job
+	Enqueue(ctx context.Context, job *model.QueueJob) error
+	// Claim leases the due job with the earliest run time until now plus lease
+	// and counts the attempt. Running jobs whose lease expired are due again.
+	// ErrNotFound is returned when no job is due.
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.QueueJob, error)
+	// Complete removes a job that finished
+	Complete(ctx context.Context, id string) error
+	// Retry releases a claimed job to run again at runAt
+	Retry(ctx context.Context, id string, runAt time.Time, lastError string) error
+	// Release returns a claimed job to the due jobs without counting the
+	// attempt, for a run that shutdown cut short
+	Release(ctx context.Context, id string, lastError string) error
+	// Bury keeps a job that will not be retried as a dead letter
+	Bury(ctx context.Context, id string, lastError string) error
+	// ListDead returns up to limit dead letters, most recently buried first
+	ListDead(ctx context.Context, limit int) ([]*model.QueueJob, error)
+}
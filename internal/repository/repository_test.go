@@ -0,0 +1,849 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/database"
+	"github.com/your-org/your-project/internal/migrate"
+	"github.com/your-org/your-project/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSQLiteRepository opens a SQL repository on a fresh SQLite database file
+func newSQLiteRepository(t *testing.T) *SQLUserRepository {
+	t.Helper()
+
+	cfg := config.DatabaseConfig{
+		Driver:       "sqlite",
+		Database:     filepath.Join(t.TempDir(), "test.db"),
+		MaxOpenConns: 1,
+	}
+
+	db, err := database.Open(cfg)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, cfg.Driver)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	return NewSQLUserRepository(db)
+}
+
+// forEachBackend runs the test against every UserRepository implementation
+func forEachBackend(t *testing.T, test func(t *testing.T, repo UserRepository)) {
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryUserRepository())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, newSQLiteRepository(t))
+	})
+}
+
+func newTestUser(email string) *model.User {
+	now := time.Now().UTC().Truncate(time.Second)
+	return &model.User{
+		Email:     email,
+		FirstName: "John",
+		LastName:  "Doe",
+		Age:       25,
+		Phone:     "+1234567890",
+		Status:    "active",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func TestCreateAndGetUser(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		user := newTestUser("john@example.com")
+		require.NoError(t, repo.Create(ctx, user))
+		assert.NotZero(t, user.ID)
+
+		found, err := repo.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.Email, found.Email)
+		assert.Equal(t, user.Phone, found.Phone)
+		assert.True(t, user.CreatedAt.Equal(found.CreatedAt))
+
+		err = repo.Create(ctx, newTestUser("john@example.com"))
+		assert.ErrorIs(t, err, ErrDuplicateEmail)
+
+		_, err = repo.Get(ctx, user.ID+100)
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestUpdateAndDeleteUser(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		first := newTestUser("first@example.com")
+		second := newTestUser("second@example.com")
+		require.NoError(t, repo.Create(ctx, first))
+		require.NoError(t, repo.Create(ctx, second))
+
+		first.FirstName = "Jane"
+		require.NoError(t, repo.Update(ctx, first))
+
+		found, err := repo.Get(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", found.FirstName)
+
+		second.Email = first.Email
+		assert.ErrorIs(t, repo.Update(ctx, second), ErrDuplicateEmail)
+
+		now := time.Now().UTC().Truncate(time.Second)
+		require.NoError(t, repo.Delete(ctx, first.ID, now))
+		assert.ErrorIs(t, repo.Delete(ctx, first.ID, now), ErrNotFound)
+		assert.ErrorIs(t, repo.Update(ctx, first), ErrNotFound)
+	})
+}
+
+func TestUpdateVersion(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		user := newTestUser("john@example.com")
+		require.NoError(t, repo.Create(ctx, user))
+		assert.Equal(t, 1, user.Version)
+
+		stale := *user
+		user.FirstName = "Jane"
+		require.NoError(t, repo.Update(ctx, user))
+		assert.Equal(t, 2, user.Version)
+
+		// A copy read before the update can no longer be written
+		stale.FirstName = "Jim"
+		assert.ErrorIs(t, repo.Update(ctx, &stale), ErrVersionConflict)
+
+		found, err := repo.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Jane", found.FirstName)
+		assert.Equal(t, 2, found.Version)
+
+		require.NoError(t, repo.Delete(ctx, user.ID, time.Now()))
+		require.NoError(t, repo.Restore(ctx, user.ID, time.Now()))
+		found, err = repo.Get(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, found.Version)
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		kept := newTestUser("kept@example.com")
+		deleted := newTestUser("deleted@example.com")
+		require.NoError(t, repo.Create(ctx, kept))
+		require.NoError(t, repo.Create(ctx, deleted))
+
+		deletedAt := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+		require.NoError(t, repo.Delete(ctx, deleted.ID, deletedAt))
+
+		// Deleted users are still found by ID but hidden from listings and counts
+		found, err := repo.Get(ctx, deleted.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found.DeletedAt)
+		assert.True(t, found.DeletedAt.Equal(deletedAt))
+
+		users, total, err := repo.List(ctx, ListOptions{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Equal(t, kept.ID, users[0].ID)
+
+		_, total, err = repo.List(ctx, ListOptions{Filter: UserFilter{IncludeDeleted: true}, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+
+		counts, err := repo.CountByStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"active": 1}, counts)
+
+		// Restoring only works for deleted users
+		assert.ErrorIs(t, repo.Restore(ctx, kept.ID, time.Now()), ErrNotFound)
+		require.NoError(t, repo.Restore(ctx, deleted.ID, time.Now()))
+		found, err = repo.Get(ctx, deleted.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found.DeletedAt)
+
+		// Only users deleted before the cutoff are purged
+		require.NoError(t, repo.Delete(ctx, deleted.ID, deletedAt))
+		purged, err := repo.Purge(ctx, deletedAt)
+		require.NoError(t, err)
+		assert.Equal(t, 0, purged)
+
+		purged, err = repo.Purge(ctx, deletedAt.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		_, err = repo.Get(ctx, deleted.ID)
+		assert.ErrorIs(t, err, ErrNotFound)
+		_, err = repo.Get(ctx, kept.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestCountByStatus(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		for i, status := range []string{"active", "active", "suspended"} {
+			user := newTestUser(fmt.Sprintf("user%d@example.com", i))
+			user.Status = status
+			require.NoError(t, repo.Create(ctx, user))
+		}
+
+		counts, err := repo.CountByStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"active": 2, "suspended": 1}, counts)
+	})
+}
+
+func TestAtomic(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+		require.NoError(t, repo.Create(ctx, newTestUser("john@example.com")))
+
+		err := repo.Atomic(ctx, func(tx UserRepository) error {
+			require.NoError(t, tx.Create(ctx, newTestUser("jane@example.com")))
+			john, err := tx.Get(ctx, 1)
+			require.NoError(t, err)
+			john.FirstName = "Johnny"
+			require.NoError(t, tx.Update(ctx, john))
+			require.NoError(t, tx.Delete(ctx, 1, time.Now()))
+			purged, err := tx.Purge(ctx, time.Now().Add(time.Hour))
+			require.NoError(t, err)
+			require.Equal(t, 1, purged)
+			return tx.Create(ctx, newTestUser("jane@example.com"))
+		})
+		assert.ErrorIs(t, err, ErrDuplicateEmail)
+
+		// Every change of the failed transaction is undone
+		_, err = repo.GetByEmail(ctx, "jane@example.com")
+		assert.ErrorIs(t, err, ErrNotFound)
+		john, err := repo.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Nil(t, john.DeletedAt)
+		assert.Equal(t, "John", john.FirstName)
+		assert.Equal(t, 1, john.Version)
+
+		err = repo.Atomic(ctx, func(tx UserRepository) error {
+			if err := tx.Create(ctx, newTestUser("jane@example.com")); err != nil {
+				return err
+			}
+			return tx.Atomic(ctx, func(nested UserRepository) error {
+				return nested.Delete(ctx, 1, time.Now())
+			})
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetByEmail(ctx, "jane@example.com")
+		assert.NoError(t, err)
+		john, err = repo.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.NotNil(t, john.DeletedAt)
+	})
+}
+
+func TestCancelledContext(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		assert.ErrorIs(t, repo.Create(ctx, newTestUser("john@example.com")), context.Canceled)
+
+		_, _, err := repo.List(ctx, ListOptions{Limit: 10})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		var ids []int
+		for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com"} {
+			user := newTestUser(email)
+			require.NoError(t, repo.Create(ctx, user))
+			ids = append(ids, user.ID)
+		}
+
+		users, total, err := repo.List(ctx, ListOptions{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, 4, total)
+		assert.Equal(t, ids[:2], userIDs(users))
+
+		users, _, err = repo.List(ctx, ListOptions{Limit: 2, Offset: 3})
+		require.NoError(t, err)
+		assert.Equal(t, ids[3:], userIDs(users))
+
+		users, _, err = repo.List(ctx, ListOptions{Limit: 2, Offset: 10})
+		require.NoError(t, err)
+		assert.Empty(t, users)
+
+		users, _, err = repo.List(ctx, ListOptions{Limit: 2, After: &model.User{ID: ids[0]}})
+		require.NoError(t, err)
+		assert.Equal(t, ids[1:3], userIDs(users))
+
+		users, _, err = repo.List(ctx, ListOptions{Limit: 2, Before: &model.User{ID: ids[3]}})
+		require.NoError(t, err)
+		assert.Equal(t, ids[1:3], userIDs(users))
+
+		users, _, err = repo.List(ctx, ListOptions{Limit: 5, Before: &model.User{ID: ids[1]}})
+		require.NoError(t, err)
+		assert.Equal(t, ids[:1], userIDs(users))
+	})
+}
+
+func TestListUsersFilterAndSort(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+
+		base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		seed := []struct {
+			email, lastName, status string
+			age                     int
+		}{
+			{"ann@example.com", "Smith", "active", 30},
+			{"bob@example.com", "Jones", "inactive", 40},
+			{"cat@example.com", "Smith", "active", 20},
+			{"dan@example.com", "Adams", "suspended", 30},
+			{"100%_real@example.com", "Brown", "active", 50},
+		}
+
+		users := make([]*model.User, 0, len(seed))
+		for i, s := range seed {
+			user := newTestUser(s.email)
+			user.LastName = s.lastName
+			user.Status = s.status
+			user.Age = s.age
+			user.CreatedAt = base.Add(time.Duration(i) * time.Hour)
+			user.UpdatedAt = user.CreatedAt
+			require.NoError(t, repo.Create(ctx, user))
+			users = append(users, user)
+		}
+
+		minAge, maxAge := 25, 45
+		createdAfter := base.Add(time.Hour)
+		list := func(opts ListOptions) ([]int, int) {
+			if opts.Limit == 0 {
+				opts.Limit = 10
+			}
+			found, total, err := repo.List(ctx, opts)
+			require.NoError(t, err)
+			return userIDs(found), total
+		}
+
+		ids, total := list(ListOptions{Filter: UserFilter{Status: []string{"active", "suspended"}}})
+		assert.Equal(t, []int{users[0].ID, users[2].ID, users[3].ID, users[4].ID}, ids)
+		assert.Equal(t, 4, total)
+
+		ids, _ = list(ListOptions{Filter: UserFilter{MinAge: &minAge, MaxAge: &maxAge}})
+		assert.Equal(t, []int{users[0].ID, users[1].ID, users[3].ID}, ids)
+
+		ids, _ = list(ListOptions{Filter: UserFilter{CreatedAfter: &createdAfter}})
+		assert.Equal(t, []int{users[1].ID, users[2].ID, users[3].ID, users[4].ID}, ids)
+
+		ids, _ = list(ListOptions{Filter: UserFilter{Search: "SMI"}})
+		assert.Equal(t, []int{users[0].ID, users[2].ID}, ids)
+
+		// Wildcards in search input are matched literally
+		ids, _ = list(ListOptions{Filter: UserFilter{Search: "100%_"}})
+		assert.Equal(t, []int{users[4].ID}, ids)
+		ids, _ = list(ListOptions{Filter: UserFilter{Search: "%"}})
+		assert.Empty(t, ids)
+
+		// Descending last name, then ascending age, then ID
+		order := []SortField{{Field: "last_name", Desc: true}, {Field: "age"}}
+		ids, _ = list(ListOptions{Sort: order})
+		expected := []int{users[2].ID, users[0].ID, users[1].ID, users[4].ID, users[3].ID}
+		assert.Equal(t, expected, ids)
+
+		// Keyset pagination follows the same ordering in both directions
+		ids, _ = list(ListOptions{Sort: order, Limit: 2, After: users[0]})
+		assert.Equal(t, expected[2:4], ids)
+		ids, _ = list(ListOptions{Sort: order, Limit: 2, Before: users[4]})
+		assert.Equal(t, expected[1:3], ids)
+
+		ids, _ = list(ListOptions{Sort: []SortField{{Field: "created_at", Desc: true}}, Limit: 2, After: users[3]})
+		assert.Equal(t, []int{users[2].ID, users[1].ID}, ids)
+	})
+}
+
+func userIDs(users []model.User) []int {
+	ids := make([]int, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestStream(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+		for i := 0; i < 5; i++ {
+			user := newTestUser(fmt.Sprintf("user%d@example.com", i))
+			user.Age = 20 + i
+			require.NoError(t, repo.Create(ctx, user))
+		}
+		require.NoError(t, repo.Delete(ctx, 2, time.Now()))
+
+		stream := func(opts ListOptions) []int {
+			var ids []int
+			require.NoError(t, repo.Stream(ctx, opts, func(user *model.User) error {
+				ids = append(ids, user.ID)
+				return nil
+			}))
+			return ids
+		}
+
+		minAge := 21
+		assert.Equal(t, []int{1, 3, 4, 5}, stream(ListOptions{Limit: 1}))
+		assert.Equal(t, []int{5, 4, 3, 2}, stream(ListOptions{
+			Filter: UserFilter{MinAge: &minAge, IncludeDeleted: true},
+			Sort:   []SortField{{Field: "age", Desc: true}},
+		}))
+
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Stream(ctx, ListOptions{}, func(*model.User) error {
+			calls++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, calls)
+	})
+}
+
+func TestStreamPages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo UserRepository) {
+		ctx := context.Background()
+		for i := 0; i < 2*streamPageSize+1; i++ {
+			require.NoError(t, repo.Create(ctx, newTestUser(fmt.Sprintf("user%d@example.com", i))))
+		}
+
+		// Users keep their order across pages, and a user deleted mid-stream is
+		// skipped. The write also proves no connection is held while fn runs, as
+		// the SQLite pool has a single connection.
+		var ids []int
+		require.NoError(t, repo.Stream(ctx, ListOptions{}, func(user *model.User) error {
+			if user.ID == 1 {
+				require.NoError(t, repo.Delete(ctx, streamPageSize+1, time.Now()))
+			}
+			ids = append(ids, user.ID)
+			return nil
+		}))
+		require.Len(t, ids, 2*streamPageSize)
+		assert.True(t, slices.IsSorted(ids))
+		assert.NotContains(t, ids, streamPageSize+1)
+	})
+}
+
+func TestRefreshTokens(t *testing.T) {
+	backends := map[string]func(t *testing.T) (UserRepository, RefreshTokenRepository){
+		"memory": func(t *testing.T) (UserRepository, RefreshTokenRepository) {
+			return NewMemoryUserRepository(), NewMemoryRefreshTokenRepository()
+		},
+		"sqlite": func(t *testing.T) (UserRepository, RefreshTokenRepository) {
+			users := newSQLiteRepository(t)
+			return users, NewSQLRefreshTokenRepository(users.pool)
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			users, tokens := open(t)
+
+			user := newTestUser("john@example.com")
+			require.NoError(t, users.Create(ctx, user))
+
+			now := time.Now().UTC().Truncate(time.Second)
+			for _, id := range []string{"a", "b"} {
+				require.NoError(t, tokens.Create(ctx, &model.RefreshToken{
+					ID:        id,
+					UserID:    user.ID,
+					FamilyID:  "family",
+					TokenHash: "hash-" + id,
+					ExpiresAt: now.Add(time.Hour),
+					CreatedAt: now,
+				}))
+			}
+
+			found, err := tokens.GetByHash(ctx, "hash-a")
+			require.NoError(t, err)
+			assert.Equal(t, "a", found.ID)
+			assert.Nil(t, found.RevokedAt)
+			assert.True(t, found.ExpiresAt.Equal(now.Add(time.Hour)))
+
+			_, err = tokens.GetByHash(ctx, "unknown")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			// A token can only be revoked once
+			require.NoError(t, tokens.Revoke(ctx, "a", now))
+			assert.ErrorIs(t, tokens.Revoke(ctx, "a", now), ErrNotFound)
+
+			require.NoError(t, tokens.RevokeFamily(ctx, "family", now))
+			found, err = tokens.GetByHash(ctx, "hash-b")
+			require.NoError(t, err)
+			assert.NotNil(t, found.RevokedAt)
+		})
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	backends := map[string]func(t *testing.T) APIKeyRepository{
+		"memory": func(t *testing.T) APIKeyRepository { return NewMemoryAPIKeyRepository() },
+		"sqlite": func(t *testing.T) APIKeyRepository { return NewSQLAPIKeyRepository(newSQLiteRepository(t).pool) },
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := open(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			expires := now.Add(time.Hour)
+			require.NoError(t, repo.Create(ctx, &model.APIKey{
+				ID: "a", Name: "sync", KeyHash: "hash-a", Scopes: []string{"users:list", "users:read"},
+				ExpiresAt: &expires, CreatedAt: now,
+			}))
+			require.NoError(t, repo.Create(ctx, &model.APIKey{
+				ID: "b", Name: "report", KeyHash: "hash-b", Scopes: []string{"users:list"}, CreatedAt: now.Add(time.Second),
+			}))
+
+			found, err := repo.GetByHash(ctx, "hash-a")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"users:list", "users:read"}, found.Scopes)
+			require.NotNil(t, found.ExpiresAt)
+			assert.True(t, found.ExpiresAt.Equal(expires))
+
+			_, err = repo.GetByHash(ctx, "unknown")
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, repo.Revoke(ctx, "a", now))
+			assert.ErrorIs(t, repo.Revoke(ctx, "a", now), ErrNotFound)
+
+			keys, err := repo.List(ctx)
+			require.NoError(t, err)
+			require.Len(t, keys, 2)
+			assert.Equal(t, "a", keys[0].ID)
+			assert.NotNil(t, keys[0].RevokedAt)
+			assert.Nil(t, keys[1].ExpiresAt)
+		})
+	}
+}
+
+func TestJobs(t *testing.T) {
+	backends := map[string]func(t *testing.T) JobRepository{
+		"memory": func(t *testing.T) JobRepository { return NewMemoryJobRepository() },
+		"sqlite": func(t *testing.T) JobRepository { return NewSQLJobRepository(newSQLiteRepository(t).pool) },
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := open(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			job := &model.Job{ID: "j1", Type: model.JobTypeUserImport, Status: model.JobQueued, DryRun: true, CreatedAt: now}
+			require.NoError(t, repo.Create(ctx, job))
+
+			found, err := repo.Get(ctx, "j1")
+			require.NoError(t, err)
+			assert.Equal(t, model.JobQueued, found.Status)
+			assert.True(t, found.DryRun)
+			assert.Nil(t, found.StartedAt)
+			assert.Empty(t, found.Errors)
+
+			finished := now.Add(time.Minute)
+			job.Status = model.JobSucceeded
+			job.Total, job.Processed, job.Succeeded, job.Failed = 3, 3, 2, 1
+			job.Errors = []model.JobItemError{{Line: 3, Error: "Validation failed", Fields: map[string]string{"Email": "Must be a valid email address"}}}
+			job.StartedAt = &now
+			job.FinishedAt = &finished
+			require.NoError(t, repo.Update(ctx, job))
+
+			// The stored job is not shared with the caller
+			job.Errors[0].Line = 99
+
+			found, err = repo.Get(ctx, "j1")
+			require.NoError(t, err)
+			assert.Equal(t, model.JobSucceeded, found.Status)
+			assert.Equal(t, 2, found.Succeeded)
+			require.Len(t, found.Errors, 1)
+			assert.Equal(t, 3, found.Errors[0].Line)
+			assert.Equal(t, "Must be a valid email address", found.Errors[0].Fields["Email"])
+			require.NotNil(t, found.FinishedAt)
+			assert.True(t, found.FinishedAt.Equal(finished))
+			assert.True(t, found.Done())
+
+			_, err = repo.Get(ctx, "unknown")
+			assert.ErrorIs(t, err, ErrNotFound)
+			assert.ErrorIs(t, repo.Update(ctx, &model.Job{ID: "unknown"}), ErrNotFound)
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	backends := map[string]func(t *testing.T) QueueRepository{
+		"memory": func(t *testing.T) QueueRepository { return NewMemoryQueueRepository() },
+		"sqlite": func(t *testing.T) QueueRepository { return NewSQLQueueRepository(newSQLiteRepository(t).pool) },
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := open(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			for id, runAt := range map[string]time.Time{"a": now.Add(-2 * time.Second), "b": now.Add(time.Hour), "c": now.Add(-time.Second)} {
+				require.NoError(t, repo.Enqueue(ctx, &model.QueueJob{
+					ID: id, Kind: "test", Payload: []byte(`{"n":1}`), Status: model.QueueJobPending,
+					MaxAttempts: 3, RunAt: runAt, CreatedAt: now, UpdatedAt: now,
+				}))
+			}
+
+			// Due jobs are claimed in run time order
+			job, err := repo.Claim(ctx, now, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "a", job.ID)
+			assert.Equal(t, model.QueueJobRunning, job.Status)
+			assert.Equal(t, 1, job.Attempts)
+			assert.Equal(t, `{"n":1}`, string(job.Payload))
+			require.NotNil(t, job.LockedUntil)
+			assert.True(t, job.LockedUntil.Equal(now.Add(time.Minute)))
+
+			job, err = repo.Claim(ctx, now, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "c", job.ID)
+
+			_, err = repo.Claim(ctx, now, time.Minute)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			// Retried jobs wait for their new run time
+			require.NoError(t, repo.Retry(ctx, "a", now.Add(10*time.Second), "boom"))
+			_, err = repo.Claim(ctx, now.Add(5*time.Second), time.Minute)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			job, err = repo.Claim(ctx, now.Add(10*time.Second), time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "a", job.ID)
+			assert.Equal(t, 2, job.Attempts)
+			assert.Equal(t, "boom", job.LastError)
+
+			require.NoError(t, repo.Complete(ctx, "c"))
+			assert.ErrorIs(t, repo.Complete(ctx, "c"), ErrNotFound)
+
+			// A job whose lease expired is taken over
+			job, err = repo.Claim(ctx, now.Add(2*time.Minute), time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "a", job.ID)
+			assert.Equal(t, 3, job.Attempts)
+
+			// A released job is due again without the attempt counting
+			require.NoError(t, repo.Release(ctx, "a", "shutdown"))
+			job, err = repo.Claim(ctx, now.Add(2*time.Minute), time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "a", job.ID)
+			assert.Equal(t, 3, job.Attempts)
+			assert.Equal(t, "shutdown", job.LastError)
+
+			// Dead letters are kept but never claimed
+			require.NoError(t, repo.Bury(ctx, "a", "gave up"))
+			dead, err := repo.ListDead(ctx, 10)
+			require.NoError(t, err)
+			require.Len(t, dead, 1)
+			assert.Equal(t, "a", dead[0].ID)
+			assert.Equal(t, model.QueueJobDead, dead[0].Status)
+			assert.Equal(t, "gave up", dead[0].LastError)
+			assert.Nil(t, dead[0].LockedUntil)
+
+			job, err = repo.Claim(ctx, now.Add(2*time.Hour), time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, "b", job.ID)
+
+			assert.ErrorIs(t, repo.Retry(ctx, "unknown", now, ""), ErrNotFound)
+			assert.ErrorIs(t, repo.Bury(ctx, "unknown", ""), ErrNotFound)
+			assert.ErrorIs(t, repo.Release(ctx, "unknown", ""), ErrNotFound)
+		})
+	}
+}
+
+func TestOutbox(t *testing.T) {
+	backends := map[string]func(t *testing.T) (UserRepository, OutboxRepository){
+		"memory": func(t *testing.T) (UserRepository, OutboxRepository) {
+			repo := NewMemoryUserRepository()
+			return repo, repo
+		},
+		"sqlite": func(t *testing.T) (UserRepository, OutboxRepository) {
+			repo := newSQLiteRepository(t)
+			return repo, NewSQLOutboxRepository(repo.pool)
+		},
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			users, outbox := open(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			newEvent := func(id string) *model.Event {
+				return &model.Event{ID: id, Type: model.EventUserCreated, UserID: 1, OccurredAt: now, Data: []byte(`{"n":1}`)}
+			}
+
+			// Events are stored with the change and discarded when it rolls back
+			require.NoError(t, users.Atomic(ctx, func(tx UserRepository) error {
+				return tx.AppendEvents(ctx, newEvent("a"), newEvent("b"))
+			}))
+			err := users.Atomic(ctx, func(tx UserRepository) error {
+				require.NoError(t, tx.AppendEvents(ctx, newEvent("rolled-back")))
+				return errors.New("boom")
+			})
+			require.Error(t, err)
+			require.NoError(t, users.AppendEvents(ctx, newEvent("c")))
+
+			pending, err := outbox.Pending(ctx, 2)
+			require.NoError(t, err)
+			require.Len(t, pending, 2)
+			assert.Equal(t, "a", pending[0].ID)
+			assert.Equal(t, "b", pending[1].ID)
+			assert.Equal(t, model.EventUserCreated, pending[0].Type)
+			assert.True(t, pending[0].OccurredAt.Equal(now))
+			assert.JSONEq(t, `{"n":1}`, string(pending[0].Data))
+
+			require.NoError(t, outbox.MarkPublished(ctx, []string{"a", "b"}, now))
+			pending, err = outbox.Pending(ctx, 10)
+			require.NoError(t, err)
+			require.Len(t, pending, 1)
+			assert.Equal(t, "c", pending[0].ID)
+
+			purged, err := outbox.PurgePublished(ctx, now)
+			require.NoError(t, err)
+			assert.Zero(t, purged)
+			purged, err = outbox.PurgePublished(ctx, now.Add(time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, 2, purged)
+
+			pending, err = outbox.Pending(ctx, 10)
+			require.NoError(t, err)
+			assert.Len(t, pending, 1)
+
+			// The lease is held by one relay at a time until it expires
+			leased, err := outbox.Lease(ctx, "relay-1", now, time.Minute)
+			require.NoError(t, err)
+			assert.True(t, leased)
+			leased, err = outbox.Lease(ctx, "relay-2", now.Add(30*time.Second), time.Minute)
+			require.NoError(t, err)
+			assert.False(t, leased)
+			leased, err = outbox.Lease(ctx, "relay-1", now.Add(30*time.Second), time.Minute)
+			require.NoError(t, err)
+			assert.True(t, leased)
+			leased, err = outbox.Lease(ctx, "relay-2", now.Add(time.Minute), time.Minute)
+			require.NoError(t, err)
+			assert.False(t, leased)
+			leased, err = outbox.Lease(ctx, "relay-2", now.Add(90*time.Second), time.Minute)
+			require.NoError(t, err)
+			assert.True(t, leased)
+		})
+	}
+}
+
+func TestWebhooks(t *testing.T) {
+	backends := map[string]func(t *testing.T) WebhookRepository{
+		"memory": func(t *testing.T) WebhookRepository { return NewMemoryWebhookRepository() },
+		"sqlite": func(t *testing.T) WebhookRepository { return NewSQLWebhookRepository(newSQLiteRepository(t).pool) },
+	}
+
+	for name, open := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := open(t)
+
+			now := time.Now().UTC().Truncate(time.Second)
+			for _, id := range []string{"a", "b"} {
+				require.NoError(t, repo.Create(ctx, &model.Webhook{
+					ID: id, URL: "https://example.com/" + id, Secret: "secret-" + id,
+					Events: []model.EventType{model.EventUserCreated, model.EventUserDeleted},
+					Active: true, CreatedAt: now, UpdatedAt: now,
+				}))
+			}
+
+			webhook, err := repo.Get(ctx, "a")
+			require.NoError(t, err)
+			assert.Equal(t, "secret-a", webhook.Secret)
+			assert.Equal(t, []model.EventType{model.EventUserCreated, model.EventUserDeleted}, webhook.Events)
+			assert.True(t, webhook.Active)
+
+			webhook.Events = []model.EventType{}
+			webhook.URL = "https://example.com/changed"
+			require.NoError(t, repo.Update(ctx, webhook))
+
+			webhooks, err := repo.List(ctx)
+			require.NoError(t, err)
+			require.Len(t, webhooks, 2)
+			assert.Equal(t, "https://example.com/changed", webhooks[0].URL)
+			assert.Empty(t, webhooks[0].Events)
+
+			// Failures are counted until a success, and the third in a row disables the webhook
+			record := func(n int, success bool) *model.Webhook {
+				t.Helper()
+				webhook, err := repo.RecordDelivery(ctx, &model.WebhookDelivery{
+					ID: fmt.Sprintf("d%d", n), WebhookID: "a", EventID: "e", EventType: model.EventUserCreated,
+					Attempt: n, Success: success, StatusCode: 500, Error: "boom", CreatedAt: now.Add(time.Duration(n) * time.Second),
+				}, 3)
+				require.NoError(t, err)
+				return webhook
+			}
+			assert.Equal(t, 1, record(1, false).FailureCount)
+			assert.Equal(t, 2, record(2, false).FailureCount)
+			assert.Zero(t, record(3, true).FailureCount)
+			record(4, false)
+			assert.True(t, record(5, false).Active)
+			webhook = record(6, false)
+			assert.False(t, webhook.Active)
+			assert.Equal(t, 3, webhook.FailureCount)
+			require.NotNil(t, webhook.DisabledAt)
+			assert.True(t, webhook.DisabledAt.Equal(now.Add(6*time.Second)))
+
+			deliveries, err := repo.ListDeliveries(ctx, "a", 2)
+			require.NoError(t, err)
+			require.Len(t, deliveries, 2)
+			assert.Equal(t, "d6", deliveries[0].ID)
+			assert.Equal(t, "d5", deliveries[1].ID)
+			assert.Equal(t, 500, deliveries[0].StatusCode)
+			assert.Equal(t, "boom", deliveries[0].Error)
+			assert.False(t, deliveries[0].Success)
+
+			purged, err := repo.PurgeDeliveries(ctx, now.Add(3*time.Second))
+			require.NoError(t, err)
+			assert.Equal(t, 2, purged)
+
+			_, err = repo.RecordDelivery(ctx, &model.WebhookDelivery{ID: "x", WebhookID: "unknown", CreatedAt: now}, 3)
+			assert.ErrorIs(t, err, ErrNotFound)
+
+			require.NoError(t, repo.Delete(ctx, "a"))
+			assert.ErrorIs(t, repo.Delete(ctx, "a"), ErrNotFound)
+			_, err = repo.Get(ctx, "a")
+			assert.ErrorIs(t, err, ErrNotFound)
+			deliveries, err = repo.ListDeliveries(ctx, "a", 10)
+			require.NoError(t, err)
+			assert.Empty(t, deliveries)
+			assert.ErrorIs(t, repo.Update(ctx, &model.Webhook{ID: "a"}), ErrNotFound)
+		})
+	}
+}
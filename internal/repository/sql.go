@@ -0,0 +1,484 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-project/internal/database"
+	"github.com/your-org/your-project/internal/model"
+)
+
+// userColumns lists the user columns in the order scanned by scanUser
+const userColumns = "id, email, first_name, last_name, age, phone, status, role, password_hash, created_at, updated_at, deleted_at, version"
+
+// queryer is implemented by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// SQLUserRepository is a UserRepository backed by a database/sql connection pool
+type SQLUserRepository struct {
+	db queryer
+	// pool starts transactions; it is nil for a repository bound to a transaction
+	pool *sql.DB
+}
+
+// NewSQLUserRepository creates a new SQL user repository
+func NewSQLUserRepository(db *sql.DB) *SQLUserRepository {
+	return &SQLUserRepository{
+		db:   db,
+		pool: db,
+	}
+}
+
+// Atomic runs fn inside a database transaction. Calls nested in a transaction
+// join it.
+func (r *SQLUserRepository) Atomic(ctx context.Context, fn func(repo UserRepository) error) error {
+	if r.pool == nil {
+		return fn(r)
+	}
+
+	tx, err := r.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(&SQLUserRepository{db: tx}); err != nil {
+		return errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+
+	return tx.Commit()
+}
+
+// Create stores a new user and assigns its ID
+func (r *SQLUserRepository) Create(ctx context.Context, user *model.User) error {
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO users (email, first_name, last_name, age, phone, status, role, password_hash, created_at, updated_at, version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, 1)
+		RETURNING id`,
+		user.Email, user.FirstName, user.LastName, user.Age, user.Phone, user.Status, user.Role,
+		user.PasswordHash, user.CreatedAt.UTC(), user.UpdatedAt.UTC(),
+	).Scan(&user.ID)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	user.Version = 1
+	return nil
+}
+
+// Get retrieves a user by ID
+func (r *SQLUserRepository) Get(ctx context.Context, id int) (*model.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email address
+func (r *SQLUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email)
+
+	user, err := scanUser(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Update persists changes to an existing user
+func (r *SQLUserRepository) Update(ctx context.Context, user *model.User) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users
+		SET email = $1, first_name = $2, last_name = $3, age = $4, phone = $5, status = $6, role = $7,
+			password_hash = $8, updated_at = $9, version = version + 1
+		WHERE id = $10 AND deleted_at IS NULL AND version = $11`,
+		user.Email, user.FirstName, user.LastName, user.Age, user.Phone, user.Status, user.Role,
+		user.PasswordHash, user.UpdatedAt.UTC(), user.ID, user.Version,
+	)
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return ErrDuplicateEmail
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		// Tell a missing user apart from one that changed since it was read
+		var version int
+		err := r.db.QueryRowContext(ctx, `SELECT version FROM users WHERE id = $1 AND deleted_at IS NULL`, user.ID).Scan(&version)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return ErrVersionConflict
+	}
+
+	user.Version++
+	return nil
+}
+
+// Delete marks a user as deleted at the given time
+func (r *SQLUserRepository) Delete(ctx context.Context, id int, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL`, at.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Restore clears the deletion mark of a deleted user
+func (r *SQLUserRepository) Restore(ctx context.Context, id int, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE users SET deleted_at = NULL, updated_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NOT NULL`, at.UTC(), id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Purge permanently removes the users deleted before the cutoff
+func (r *SQLUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE deleted_at < $1`, deletedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(purged), nil
+}
+
+// List returns a window of the users matching the filter along with the total number of matches
+func (r *SQLUserRepository) List(ctx context.Context, opts ListOptions) ([]model.User, int, error) {
+	where := &whereBuilder{}
+	where.filter(&opts.Filter)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users`+where.String(), where.args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	users, err := r.window(ctx, opts, where)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+// window returns the users of the window opts selects among those matching
+// the conditions in where
+func (r *SQLUserRepository) window(ctx context.Context, opts ListOptions, where *whereBuilder) ([]model.User, error) {
+	keys := opts.sortKeys()
+	if err := checkSortKeys(keys); err != nil {
+		return nil, err
+	}
+
+	// Walk backwards from a Before anchor, then restore the requested order below
+	backwards := opts.Before != nil
+	switch {
+	case opts.After != nil:
+		where.keyset(keys, opts.After, false)
+	case opts.Before != nil:
+		where.keyset(keys, opts.Before, true)
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users` + where.String() + orderBy(keys, backwards) +
+		` LIMIT ` + where.arg(opts.Limit)
+	if opts.After == nil && opts.Before == nil {
+		query += ` OFFSET ` + where.arg(opts.Offset)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, where.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, opts.Limit)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if backwards {
+		slices.Reverse(users)
+	}
+
+	return users, nil
+}
+
+// Stream calls fn for every user matching the filter, in sort order. Users
+// are read a page at a time, each page positioned after the last user of the
+// previous one like a List cursor, so no connection is held while fn runs.
+func (r *SQLUserRepository) Stream(ctx context.Context, opts ListOptions, fn func(user *model.User) error) error {
+	opts.Limit, opts.Offset = streamPageSize, 0
+	opts.After, opts.Before = nil, nil
+
+	for {
+		where := &whereBuilder{}
+		where.filter(&opts.Filter)
+
+		users, err := r.window(ctx, opts, where)
+		if err != nil {
+			return err
+		}
+
+		for i := range users {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := fn(&users[i]); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < streamPageSize {
+			return nil
+		}
+		opts.After = &users[len(users)-1]
+	}
+}
+
+// CountByStatus returns the number of users in each status
+func (r *SQLUserRepository) CountByStatus(ctx context.Context) (map[string]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT status, COUNT(*) FROM users WHERE deleted_at IS NULL GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// whereBuilder accumulates SQL conditions and their positional arguments
+type whereBuilder struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg registers a query argument and returns its placeholder
+func (b *whereBuilder) arg(value interface{}) string {
+	b.args = append(b.args, value)
+	return "$" + strconv.Itoa(len(b.args))
+}
+
+// String renders the WHERE clause, or nothing when there are no conditions
+func (b *whereBuilder) String() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(b.conditions, " AND ")
+}
+
+// filter adds the conditions of a user filter
+func (b *whereBuilder) filter(filter *UserFilter) {
+	if !filter.IncludeDeleted {
+		b.conditions = append(b.conditions, "deleted_at IS NULL")
+	}
+	if len(filter.Status) > 0 {
+		placeholders := make([]string, 0, len(filter.Status))
+		for _, status := range filter.Status {
+			placeholders = append(placeholders, b.arg(status))
+		}
+		b.conditions = append(b.conditions, "status IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if filter.MinAge != nil {
+		b.conditions = append(b.conditions, "age >= "+b.arg(*filter.MinAge))
+	}
+	if filter.MaxAge != nil {
+		b.conditions = append(b.conditions, "age <= "+b.arg(*filter.MaxAge))
+	}
+	if filter.CreatedAfter != nil {
+		b.conditions = append(b.conditions, "created_at >= "+b.arg(filter.CreatedAfter.UTC()))
+	}
+	if filter.CreatedBefore != nil {
+		b.conditions = append(b.conditions, "created_at < "+b.arg(filter.CreatedBefore.UTC()))
+	}
+	if filter.UpdatedAfter != nil {
+		b.conditions = append(b.conditions, "updated_at >= "+b.arg(filter.UpdatedAfter.UTC()))
+	}
+	if filter.UpdatedBefore != nil {
+		b.conditions = append(b.conditions, "updated_at < "+b.arg(filter.UpdatedBefore.UTC()))
+	}
+	if filter.Search != "" {
+		pattern := b.arg(likeEscaper.Replace(strings.ToLower(filter.Search)) + "%")
+		b.conditions = append(b.conditions, fmt.Sprintf(
+			`(LOWER(first_name) LIKE %[1]s ESCAPE '\' OR LOWER(last_name) LIKE %[1]s ESCAPE '\' OR LOWER(email) LIKE %[1]s ESCAPE '\')`,
+			pattern,
+		))
+	}
+}
+
+// keyset adds the condition selecting users after (or, when backwards, before)
+// the anchor in the given ordering. For keys k1..kn it expands to
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... with comparisons flipped for
+// descending keys.
+func (b *whereBuilder) keyset(keys []SortField, anchor *model.User, backwards bool) {
+	placeholders := make([]string, len(keys))
+	for i, key := range keys {
+		placeholders[i] = b.arg(sortValue(anchor, key.Field))
+	}
+
+	alternatives := make([]string, 0, len(keys))
+	for i, key := range keys {
+		parts := make([]string, 0, i+1)
+		for j := 0; j < i; j++ {
+			parts = append(parts, keys[j].Field+" = "+placeholders[j])
+		}
+
+		op := ">"
+		if key.Desc != backwards {
+			op = "<"
+		}
+		parts = append(parts, key.Field+" "+op+" "+placeholders[i])
+
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+	}
+
+	b.conditions = append(b.conditions, "("+strings.Join(alternatives, " OR ")+")")
+}
+
+// checkSortKeys rejects orderings by fields that cannot be sorted on
+func checkSortKeys(keys []SortField) error {
+	for _, key := range keys {
+		if !UserSortFields[key.Field] {
+			return fmt.Errorf("unsupported sort field: %s", key.Field)
+		}
+	}
+	return nil
+}
+
+// likeEscaper escapes LIKE wildcards so search input is matched literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// orderBy renders the ORDER BY clause, reversing every key when backwards
+func orderBy(keys []SortField, backwards bool) string {
+	terms := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := "ASC"
+		if key.Desc != backwards {
+			direction = "DESC"
+		}
+		terms = append(terms, key.Field+" "+direction)
+	}
+	return " ORDER BY " + strings.Join(terms, ", ")
+}
+
+// sortValue returns the value of a sortable user field as a query argument
+func sortValue(user *model.User, field string) interface{} {
+	switch field {
+	case "email":
+		return user.Email
+	case "first_name":
+		return user.FirstName
+	case "last_name":
+		return user.LastName
+	case "age":
+		return user.Age
+	case "status":
+		return user.Status
+	case "created_at":
+		return user.CreatedAt.UTC()
+	case "updated_at":
+		return user.UpdatedAt.UTC()
+	default:
+		return user.ID
+	}
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser reads a user from a row selected with userColumns
+func scanUser(row rowScanner) (*model.User, error) {
+	var user model.User
+	err := row.Scan(
+		&user.ID, &user.Email, &user.FirstName, &user.LastName, &user.Age,
+		&user.Phone, &user.Status, &user.Role, &user.PasswordHash, &user.CreatedAt, &user.UpdatedAt,
+		&user.DeletedAt, &user.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// ignoreDone drops the error of rolling back a transaction that already ended,
+// e.g. because its context was cancelled
+func ignoreDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+	return err
+}
+
+// expectAffected returns ErrNotFound when a statement matched no rows
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
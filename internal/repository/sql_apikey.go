@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// apiKeyColumns lists the API key columns in the order scanned by scanAPIKey
+const apiKeyColumns = "id, name, key_hash, scopes, expires_at, created_at, revoked_at"
+
+// SQLAPIKeyRepository is an APIKeyRepository backed by a database/sql connection pool
+type SQLAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLAPIKeyRepository creates a new SQL API key repository
+func NewSQLAPIKeyRepository(db *sql.DB) *SQLAPIKeyRepository {
+	return &SQLAPIKeyRepository{
+		db: db,
+	}
+}
+
+// Create stores a new API key
+func (r *SQLAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	var expiresAt sql.NullTime
+	if key.ExpiresAt != nil {
+		expiresAt = sql.NullTime{Time: key.ExpiresAt.UTC(), Valid: true}
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, name, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		key.ID, key.Name, key.KeyHash, strings.Join(key.Scopes, " "), expiresAt, key.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// GetByHash retrieves an API key by the hash of its value
+func (r *SQLAPIKeyRepository) GetByHash(ctx context.Context, hash string) (*model.APIKey, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash)
+
+	key, err := scanAPIKey(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// List returns every API key, oldest first
+func (r *SQLAPIKeyRepository) List(ctx context.Context) ([]model.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]model.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// Revoke marks a key as revoked
+func (r *SQLAPIKeyRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		at.UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// scanAPIKey reads an API key from a row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*model.APIKey, error) {
+	var key model.APIKey
+	var scopes string
+	var expiresAt, revokedAt sql.NullTime
+
+	err := row.Scan(&key.ID, &key.Name, &key.KeyHash, &scopes, &expiresAt, &key.CreatedAt, &revokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// jobColumns lists the job columns in the order scanned by Get
+const jobColumns = "id, type, status, dry_run, total, processed, succeeded, failed, errors, error, created_at, started_at, finished_at"
+
+// SQLJobRepository is a JobRepository backed by a database/sql connection pool
+type SQLJobRepository struct {
+	db *sql.DB
+}
+
+// NewSQLJobRepository creates a new SQL job repository
+func NewSQLJobRepository(db *sql.DB) *SQLJobRepository {
+	return &SQLJobRepository{
+		db: db,
+	}
+}
+
+// Create stores a new job
+func (r *SQLJobRepository) Create(ctx context.Context, job *model.Job) error {
+	itemErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO jobs (`+jobColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		job.ID, job.Type, job.Status, job.DryRun, job.Total, job.Processed, job.Succeeded, job.Failed,
+		string(itemErrors), job.Error, job.CreatedAt.UTC(), nullTime(job.StartedAt), nullTime(job.FinishedAt),
+	)
+	return err
+}
+
+// Get retrieves a job by ID
+func (r *SQLJobRepository) Get(ctx context.Context, id string) (*model.Job, error) {
+	var job model.Job
+	var itemErrors string
+	var startedAt, finishedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id).Scan(
+		&job.ID, &job.Type, &job.Status, &job.DryRun, &job.Total, &job.Processed, &job.Succeeded, &job.Failed,
+		&itemErrors, &job.Error, &job.CreatedAt, &startedAt, &finishedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(itemErrors), &job.Errors); err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return &job, nil
+}
+
+// Update stores the status and progress of an existing job
+func (r *SQLJobRepository) Update(ctx context.Context, job *model.Job) error {
+	itemErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE jobs
+		SET status = $1, total = $2, processed = $3, succeeded = $4, failed = $5, errors = $6, error = $7,
+			started_at = $8, finished_at = $9
+		WHERE id = $10`,
+		job.Status, job.Total, job.Processed, job.Succeeded, job.Failed, string(itemErrors), job.Error,
+		nullTime(job.StartedAt), nullTime(job.FinishedAt), job.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// nullTime converts an optional time to its UTC storage form
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
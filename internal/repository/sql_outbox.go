@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// AppendEvents stores events in the outbox, inside the transaction when called within Atomic
+func (r *SQLUserRepository) AppendEvents(ctx context.Context, events ...*model.Event) error {
+	for _, event := range events {
+		_, err := r.db.ExecContext(ctx,
+			`INSERT INTO outbox_events (id, type, user_id, occurred_at, data) VALUES ($1, $2, $3, $4, $5)`,
+			event.ID, event.Type, event.UserID, event.OccurredAt.UTC(), string(event.Data),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SQLOutboxRepository is an OutboxRepository backed by a database/sql connection pool
+type SQLOutboxRepository struct {
+	db *sql.DB
+}
+
+// NewSQLOutboxRepository creates a new SQL outbox repository
+func NewSQLOutboxRepository(db *sql.DB) *SQLOutboxRepository {
+	return &SQLOutboxRepository{
+		db: db,
+	}
+}
+
+// Lease makes holder the only relay publishing from the outbox until now+ttl
+// and reports whether it is. The conditional update lets only one of several
+// servers hold the lease at a time.
+func (r *SQLOutboxRepository) Lease(ctx context.Context, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	now = now.UTC()
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE outbox_lease SET holder = $1, locked_until = $2
+		WHERE id = 1 AND (holder = $1 OR locked_until IS NULL OR locked_until <= $3)`,
+		holder, now.Add(ttl), now,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	leased, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return leased == 1, nil
+}
+
+// Pending returns up to limit unpublished events in the order they were stored
+func (r *SQLOutboxRepository) Pending(ctx context.Context, limit int) ([]*model.Event, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, type, user_id, occurred_at, data FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY seq
+		LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.Event
+	for rows.Next() {
+		var event model.Event
+		var data string
+		if err := rows.Scan(&event.ID, &event.Type, &event.UserID, &event.OccurredAt, &data); err != nil {
+			return nil, err
+		}
+		event.Data = []byte(data)
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkPublished records that the events with the given IDs were published
+func (r *SQLOutboxRepository) MarkPublished(ctx context.Context, ids []string, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var b whereBuilder
+	publishedAt := b.arg(at.UTC())
+	placeholders := make([]string, 0, len(ids))
+	for _, id := range ids {
+		placeholders = append(placeholders, b.arg(id))
+	}
+	b.conditions = append(b.conditions, "id IN ("+strings.Join(placeholders, ", ")+")", "published_at IS NULL")
+
+	_, err := r.db.ExecContext(ctx, `UPDATE outbox_events SET published_at = `+publishedAt+b.String(), b.args...)
+	return err
+}
+
+// PurgePublished removes events published before the cutoff and returns how many were removed
+func (r *SQLOutboxRepository) PurgePublished(ctx context.Context, publishedBefore time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, publishedBefore.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(purged), nil
+}
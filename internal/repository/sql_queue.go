@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// queueJobColumns lists the queue job columns in the order scanned by scanQueueJob
+const queueJobColumns = "id, kind, payload, status, attempts, max_attempts, run_at, locked_until, last_error, created_at, updated_at"
+
+// SQLQueueRepository is a QueueRepository backed by a database/sql connection
+// pool. Claims are made with a conditional update, so several servers can
+// share one queue.
+type SQLQueueRepository struct {
+	db *sql.DB
+}
+
+// NewSQLQueueRepository creates a new SQL queue repository
+func NewSQLQueueRepository(db *sql.DB) *SQLQueueRepository {
+	return &SQLQueueRepository{
+		db: db,
+	}
+}
+
+// Enqueue stores a new pending job
+func (r *SQLQueueRepository) Enqueue(ctx context.Context, job *model.QueueJob) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO queue_jobs (`+queueJobColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		job.ID, job.Kind, job.Payload, job.Status, job.Attempts, job.MaxAttempts, job.RunAt.UTC(),
+		nullTime(job.LockedUntil), job.LastError, job.CreatedAt.UTC(), job.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// Claim leases the due job with the earliest run time
+func (r *SQLQueueRepository) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.QueueJob, error) {
+	now = now.UTC()
+	for {
+		var id string
+		err := r.db.QueryRowContext(ctx,
+			`SELECT id FROM queue_jobs
+			WHERE (status = $1 AND run_at <= $2) OR (status = $3 AND locked_until <= $2)
+			ORDER BY run_at, id
+			LIMIT 1`,
+			model.QueueJobPending, now, model.QueueJobRunning,
+		).Scan(&id)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+
+		// The condition is checked again in case another worker claimed the job first
+		result, err := r.db.ExecContext(ctx,
+			`UPDATE queue_jobs
+			SET status = $1, attempts = attempts + 1, locked_until = $2, updated_at = $3
+			WHERE id = $4 AND ((status = $5 AND run_at <= $3) OR (status = $1 AND locked_until <= $3))`,
+			model.QueueJobRunning, now.Add(lease), now, id, model.QueueJobPending,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := expectAffected(result); err != nil {
+			continue
+		}
+
+		return scanQueueJob(r.db.QueryRowContext(ctx, `SELECT `+queueJobColumns+` FROM queue_jobs WHERE id = $1`, id))
+	}
+}
+
+// Complete removes a job that finished
+func (r *SQLQueueRepository) Complete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM queue_jobs WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Retry releases a claimed job to run again at runAt
+func (r *SQLQueueRepository) Retry(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE queue_jobs SET status = $1, run_at = $2, locked_until = NULL, last_error = $3, updated_at = $4 WHERE id = $5`,
+		model.QueueJobPending, runAt.UTC(), lastError, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Release returns a claimed job to the due jobs without counting the attempt
+func (r *SQLQueueRepository) Release(ctx context.Context, id string, lastError string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE queue_jobs SET status = $1, attempts = attempts - 1, locked_until = NULL, last_error = $2, updated_at = $3
+		WHERE id = $4 AND attempts > 0`,
+		model.QueueJobPending, lastError, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Bury keeps a job that will not be retried as a dead letter
+func (r *SQLQueueRepository) Bury(ctx context.Context, id string, lastError string) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE queue_jobs SET status = $1, locked_until = NULL, last_error = $2, updated_at = $3 WHERE id = $4`,
+		model.QueueJobDead, lastError, time.Now().UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// ListDead returns up to limit dead letters, most recently buried first
+func (r *SQLQueueRepository) ListDead(ctx context.Context, limit int) ([]*model.QueueJob, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+queueJobColumns+` FROM queue_jobs WHERE status = $1 ORDER BY updated_at DESC, id LIMIT $2`,
+		model.QueueJobDead, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*model.QueueJob
+	for rows.Next() {
+		job, err := scanQueueJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+// scanQueueJob reads a row selected with queueJobColumns
+func scanQueueJob(row rowScanner) (*model.QueueJob, error) {
+	var job model.QueueJob
+	var lockedUntil sql.NullTime
+
+	err := row.Scan(
+		&job.ID, &job.Kind, &job.Payload, &job.Status, &job.Attempts, &job.MaxAttempts, &job.RunAt,
+		&lockedUntil, &job.LastError, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		job.LockedUntil = &lockedUntil.Time
+	}
+
+	return &job, nil
+}
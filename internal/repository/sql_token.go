@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// SQLRefreshTokenRepository is a RefreshTokenRepository backed by a database/sql connection pool
+type SQLRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLRefreshTokenRepository creates a new SQL refresh token repository
+func NewSQLRefreshTokenRepository(db *sql.DB) *SQLRefreshTokenRepository {
+	return &SQLRefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Create stores a new refresh token
+func (r *SQLRefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt.UTC(), token.CreatedAt.UTC(),
+	)
+	return err
+}
+
+// GetByHash retrieves a refresh token by the hash of its value
+func (r *SQLRefreshTokenRepository) GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error) {
+	var token model.RefreshToken
+	var revokedAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT id, user_id, family_id, token_hash, expires_at, created_at, revoked_at
+		FROM refresh_tokens WHERE token_hash = $1`,
+		hash,
+	).Scan(&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash, &token.ExpiresAt, &token.CreatedAt, &revokedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return &token, nil
+}
+
+// Revoke marks a token as revoked
+func (r *SQLRefreshTokenRepository) Revoke(ctx context.Context, id string, at time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		at.UTC(), id,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// RevokeFamily revokes every token of a login session
+func (r *SQLRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`,
+		at.UTC(), familyID,
+	)
+	return err
+}
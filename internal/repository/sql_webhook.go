@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// webhookColumns lists the webhook columns in the order scanned by scanWebhook
+const webhookColumns = "id, url, secret, events, active, failure_count, disabled_at, created_at, updated_at"
+
+// webhookDeliveryColumns lists the delivery columns in the order scanned by ListDeliveries
+const webhookDeliveryColumns = "id, webhook_id, event_id, event_type, attempt, success, status_code, error, duration_ms, created_at"
+
+// SQLWebhookRepository is a WebhookRepository backed by a database/sql connection pool
+type SQLWebhookRepository struct {
+	db *sql.DB
+}
+
+// NewSQLWebhookRepository creates a new SQL webhook repository
+func NewSQLWebhookRepository(db *sql.DB) *SQLWebhookRepository {
+	return &SQLWebhookRepository{
+		db: db,
+	}
+}
+
+// Create stores a new webhook
+func (r *SQLWebhookRepository) Create(ctx context.Context, webhook *model.Webhook) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO webhooks (`+webhookColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		webhook.ID, webhook.URL, webhook.Secret, joinEventTypes(webhook.Events), webhook.Active, webhook.FailureCount,
+		nullTime(webhook.DisabledAt), webhook.CreatedAt.UTC(), webhook.UpdatedAt.UTC(),
+	)
+	return err
+}
+
+// Get retrieves a webhook by ID
+func (r *SQLWebhookRepository) Get(ctx context.Context, id string) (*model.Webhook, error) {
+	return getWebhook(ctx, r.db, id)
+}
+
+// List returns every webhook, oldest first
+func (r *SQLWebhookRepository) List(ctx context.Context) ([]model.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := make([]model.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// Update stores the settings and state of an existing webhook
+func (r *SQLWebhookRepository) Update(ctx context.Context, webhook *model.Webhook) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE webhooks SET url = $1, secret = $2, events = $3, active = $4, failure_count = $5, disabled_at = $6, updated_at = $7
+		WHERE id = $8`,
+		webhook.URL, webhook.Secret, joinEventTypes(webhook.Events), webhook.Active, webhook.FailureCount,
+		nullTime(webhook.DisabledAt), webhook.UpdatedAt.UTC(), webhook.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result)
+}
+
+// Delete removes a webhook and its delivery log
+func (r *SQLWebhookRepository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err == nil {
+		err = expectAffected(result)
+	}
+	if err == nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE webhook_id = $1`, id)
+	}
+	if err != nil {
+		return errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+
+	return tx.Commit()
+}
+
+// RecordDelivery logs a delivery attempt and updates the webhook's failure count
+func (r *SQLWebhookRepository) RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (*model.Webhook, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := recordDelivery(ctx, tx, delivery, disableAfter)
+	if err != nil {
+		return nil, errors.Join(err, ignoreDone(tx.Rollback()))
+	}
+
+	return webhook, tx.Commit()
+}
+
+// recordDelivery performs RecordDelivery within tx
+func recordDelivery(ctx context.Context, tx *sql.Tx, delivery *model.WebhookDelivery, disableAfter int) (*model.Webhook, error) {
+	query := `UPDATE webhooks SET failure_count = failure_count + 1 WHERE id = $1`
+	if delivery.Success {
+		query = `UPDATE webhooks SET failure_count = 0 WHERE id = $1`
+	}
+	result, err := tx.ExecContext(ctx, query, delivery.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectAffected(result); err != nil {
+		return nil, err
+	}
+
+	if !delivery.Success && disableAfter > 0 {
+		_, err := tx.ExecContext(ctx,
+			`UPDATE webhooks SET active = FALSE, disabled_at = $1, updated_at = $1
+			WHERE id = $2 AND active AND failure_count >= $3`,
+			delivery.CreatedAt.UTC(), delivery.WebhookID, disableAfter,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (`+webhookDeliveryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		delivery.ID, delivery.WebhookID, delivery.EventID, delivery.EventType, delivery.Attempt, delivery.Success,
+		delivery.StatusCode, delivery.Error, delivery.DurationMS, delivery.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return getWebhook(ctx, tx, delivery.WebhookID)
+}
+
+// ListDeliveries returns up to limit delivery attempts of a webhook, newest first
+func (r *SQLWebhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY seq DESC
+		LIMIT $2`,
+		webhookID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0)
+	for rows.Next() {
+		var delivery model.WebhookDelivery
+		err := rows.Scan(&delivery.ID, &delivery.WebhookID, &delivery.EventID, &delivery.EventType, &delivery.Attempt,
+			&delivery.Success, &delivery.StatusCode, &delivery.Error, &delivery.DurationMS, &delivery.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// PurgeDeliveries removes delivery attempts made before the cutoff
+func (r *SQLWebhookRepository) PurgeDeliveries(ctx context.Context, before time.Time) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE created_at < $1`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(purged), nil
+}
+
+// getWebhook retrieves a webhook by ID through db, which may be a transaction
+func getWebhook(ctx context.Context, db queryer, id string) (*model.Webhook, error) {
+	row := db.QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id)
+
+	webhook, err := scanWebhook(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return webhook, nil
+}
+
+// scanWebhook reads a webhook from a row selected with webhookColumns
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	var webhook model.Webhook
+	var events string
+	var disabledAt sql.NullTime
+
+	err := row.Scan(&webhook.ID, &webhook.URL, &webhook.Secret, &events, &webhook.Active, &webhook.FailureCount,
+		&disabledAt, &webhook.CreatedAt, &webhook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Events = make([]model.EventType, 0)
+	for _, event := range strings.Fields(events) {
+		webhook.Events = append(webhook.Events, model.EventType(event))
+	}
+	if disabledAt.Valid {
+		webhook.DisabledAt = &disabledAt.Time
+	}
+
+	return &webhook, nil
+}
+
+// joinEventTypes stores an event filter as a space separated list
+func joinEventTypes(events []model.EventType) string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = string(event)
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// RefreshTokenRepository defines the storage operations for refresh tokens
+type RefreshTokenRepository interface {
+	// Create stores a new refresh token
+	Create(ctx context.Context, token *model.RefreshToken) error
+	// GetByHash retrieves a refresh token by the hash of its value
+	GetByHash(ctx context.Context, hash string) (*model.RefreshToken, error)
+	// Revoke marks a token as revoked. It returns ErrNotFound when the token
+	// does not exist or was already revoked, so only one caller can rotate it.
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// RevokeFamily revokes every token of a login session
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+}
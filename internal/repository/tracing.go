@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// tracer creates the storage spans
+var tracer = otel.Tracer("github.com/your-org/your-project/internal/repository")
+
+// TracedUserRepository wraps a UserRepository, recording a span for every call
+type TracedUserRepository struct {
+	next   UserRepository
+	system string
+}
+
+// NewTracedUserRepository wraps repo with tracing. system names the storage
+// backend (e.g. "postgresql", "sqlite" or "memory") in the db.system attribute.
+func NewTracedUserRepository(repo UserRepository, system string) *TracedUserRepository {
+	return &TracedUserRepository{
+		next:   repo,
+		system: system,
+	}
+}
+
+// Create stores a new user and assigns its ID
+func (r *TracedUserRepository) Create(ctx context.Context, user *model.User) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Create")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Create(ctx, user)
+}
+
+// Get retrieves a user by ID
+func (r *TracedUserRepository) Get(ctx context.Context, id int) (_ *model.User, err error) {
+	ctx, span := r.start(ctx, "UserRepository.Get", attribute.Int("user.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Get(ctx, id)
+}
+
+// GetByEmail retrieves a user by email address
+func (r *TracedUserRepository) GetByEmail(ctx context.Context, email string) (_ *model.User, err error) {
+	ctx, span := r.start(ctx, "UserRepository.GetByEmail")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.GetByEmail(ctx, email)
+}
+
+// Update persists changes to an existing user
+func (r *TracedUserRepository) Update(ctx context.Context, user *model.User) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Update", attribute.Int("user.id", user.ID))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Update(ctx, user)
+}
+
+// Delete marks a user as deleted at the given time
+func (r *TracedUserRepository) Delete(ctx context.Context, id int, at time.Time) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Delete", attribute.Int("user.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Delete(ctx, id, at)
+}
+
+// Restore clears the deletion mark of a deleted user
+func (r *TracedUserRepository) Restore(ctx context.Context, id int, at time.Time) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Restore", attribute.Int("user.id", id))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Restore(ctx, id, at)
+}
+
+// Purge permanently removes the users deleted before the cutoff
+func (r *TracedUserRepository) Purge(ctx context.Context, deletedBefore time.Time) (_ int, err error) {
+	ctx, span := r.start(ctx, "UserRepository.Purge")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Purge(ctx, deletedBefore)
+}
+
+// List returns a window of the users matching the filter along with the total number of matches
+func (r *TracedUserRepository) List(ctx context.Context, opts ListOptions) (_ []model.User, _ int, err error) {
+	ctx, span := r.start(ctx, "UserRepository.List", attribute.Int("db.limit", opts.Limit))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.List(ctx, opts)
+}
+
+// Stream calls fn for every user matching the filter
+func (r *TracedUserRepository) Stream(ctx context.Context, opts ListOptions, fn func(user *model.User) error) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Stream")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Stream(ctx, opts, fn)
+}
+
+// CountByStatus returns the number of users in each status
+func (r *TracedUserRepository) CountByStatus(ctx context.Context) (_ map[string]int, err error) {
+	ctx, span := r.start(ctx, "UserRepository.CountByStatus")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.CountByStatus(ctx)
+}
+
+// Atomic runs fn in a transaction, tracing the calls fn makes
+func (r *TracedUserRepository) Atomic(ctx context.Context, fn func(repo UserRepository) error) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.Atomic")
+	defer func() { endSpan(span, err) }()
+
+	return r.next.Atomic(ctx, func(repo UserRepository) error {
+		return fn(NewTracedUserRepository(repo, r.system))
+	})
+}
+
+// AppendEvents stores events in the outbox
+func (r *TracedUserRepository) AppendEvents(ctx context.Context, events ...*model.Event) (err error) {
+	ctx, span := r.start(ctx, "UserRepository.AppendEvents", attribute.Int("events.count", len(events)))
+	defer func() { endSpan(span, err) }()
+
+	return r.next.AppendEvents(ctx, events...)
+}
+
+// start begins a client span for a storage call
+func (r *TracedUserRepository) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, attribute.String("db.system", r.system))
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records a storage failure on the span and ends it. Not-found,
+// duplicate and version conflict errors are expected outcomes and leave the
+// span status unset.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrDuplicateEmail) && !errors.Is(err, ErrVersionConflict) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+var (
+	// ErrNotFound is returned when the requested user does not exist
+	ErrNotFound = errors.New("user not found")
+	// ErrDuplicateEmail is returned when another user already owns the email
+	ErrDuplicateEmail = errors.New("email already exists")
+	// ErrVersionConflict is returned when a user changed since the version being updated was read
+	ErrVersionConflict = errors.New("version conflict")
+)
+
+// UserRepository defines the storage operations for users.
+// Get and GetByEmail return deleted users too; List excludes them unless the
+// filter asks for them. Every change to a user increments its version.
+type UserRepository interface {
+	// Create stores a new user and assigns its ID and first version
+	Create(ctx context.Context, user *model.User) error
+	// Get retrieves a user by ID
+	Get(ctx context.Context, id int) (*model.User, error)
+	// GetByEmail retrieves a user by email address
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	// Update persists changes to an existing user that has not been deleted.
+	// It fails with ErrVersionConflict unless the stored version equals
+	// user.Version, and increments user.Version on success.
+	Update(ctx context.Context, user *model.User) error
+	// Delete marks a user as deleted at the given time. Deleted users keep their
+	// email address until they are purged.
+	Delete(ctx context.Context, id int, at time.Time) error
+	// Restore clears the deletion mark of a deleted user
+	Restore(ctx context.Context, id int, at time.Time) error
+	// Purge permanently removes the users deleted before the cutoff and returns how many were removed
+	Purge(ctx context.Context, deletedBefore time.Time) (int, error)
+	// List returns a window of the users matching the filter along with the total number of matches
+	List(ctx context.Context, opts ListOptions) ([]model.User, int, error)
+	// Stream calls fn for every user matching the filter, in sort order, without
+	// loading them all at once. The window fields of opts are ignored. Streaming
+	// stops at the first error fn returns.
+	Stream(ctx context.Context, opts ListOptions, fn func(user *model.User) error) error
+	// CountByStatus returns the number of users in each status, excluding deleted users
+	CountByStatus(ctx context.Context) (map[string]int, error)
+	// Atomic runs fn with a repository whose changes are committed together when
+	// fn returns nil and discarded otherwise. fn must only use the repository it
+	// is given.
+	Atomic(ctx context.Context, fn func(repo UserRepository) error) error
+	// AppendEvents stores events in the outbox read by OutboxRepository. Inside
+	// Atomic they are committed together with the changes they describe.
+	AppendEvents(ctx context.Context, events ...*model.Event) error
+}
+
+// UserSortFields lists the user fields List can order by
+var UserSortFields = map[string]bool{
+	"id":         true,
+	"email":      true,
+	"first_name": true,
+	"last_name":  true,
+	"age":        true,
+	"status":     true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// UserFilter restricts the users returned by List. Zero values are ignored.
+type UserFilter struct {
+	// Status matches users with any of the given statuses
+	Status        []string
+	MinAge        *int
+	MaxAge        *int
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	// Search matches a case-insensitive prefix of the first name, last name or email
+	Search string
+	// IncludeDeleted also matches users that have been deleted
+	IncludeDeleted bool
+}
+
+// SortField orders users by one field
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// streamPageSize is how many users Stream reads from storage at a time
+const streamPageSize = 500
+
+// ListOptions selects the window of users returned by List.
+// Users are ordered by Sort with the ID as the final tie-breaker. When After
+// or Before is set the window is anchored to that user's position in the
+// ordering (keyset pagination) and Offset is ignored; only the ID and the
+// sorted fields of the anchor need to be populated.
+type ListOptions struct {
+	Filter UserFilter
+	Sort   []SortField
+	Limit  int
+	Offset int
+	// After selects users positioned strictly after the anchor
+	After *model.User
+	// Before selects the users positioned immediately before the anchor
+	Before *model.User
+}
+
+// sortKeys returns the effective ordering, always ending with the ID
+func (o ListOptions) sortKeys() []SortField {
+	keys := make([]SortField, 0, len(o.Sort)+1)
+	for _, field := range o.Sort {
+		keys = append(keys, field)
+		if field.Field == "id" {
+			return keys
+		}
+	}
+	return append(keys, SortField{Field: "id"})
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// WebhookRepository defines the storage operations for webhooks and their delivery logs
+type WebhookRepository interface {
+	// Create stores a new webhook
+	Create(ctx context.Context, webhook *model.Webhook) error
+	// Get retrieves a webhook by ID
+	Get(ctx context.Context, id string) (*model.Webhook, error)
+	// List returns every webhook, oldest first
+	List(ctx context.Context) ([]model.Webhook, error)
+	// Update stores the settings and state of an existing webhook
+	Update(ctx context.Context, webhook *model.Webhook) error
+	// Delete removes a webhook and its delivery log
+	Delete(ctx context.Context, id string) error
+	// RecordDelivery logs a delivery attempt and updates the webhook's failure
+	// count, resetting it on success. The webhook is disabled once the count
+	// reaches disableAfter, unless that is zero. It returns the updated
+	// webhook, or ErrNotFound when the webhook no longer exists.
+	RecordDelivery(ctx context.Context, delivery *model.WebhookDelivery, disableAfter int) (*model.Webhook, error)
+	// ListDeliveries returns up to limit delivery attempts of a webhook, newest first
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]model.WebhookDelivery, error)
+	// PurgeDeliveries removes delivery attempts made before the cutoff and returns how many were removed
+	PurgeDeliveries(ctx context.Context, before time.Time) (int, error)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// apiKeyPrefix marks API key values so they are recognisable in configs and logs
+const apiKeyPrefix = "ak_"
+
+// APIKeyService issues, revokes and verifies API keys for service callers
+type APIKeyService struct {
+	repo   repository.APIKeyRepository
+	logger *slog.Logger
+}
+
+// NewAPIKeyService creates a new API key service backed by the given repository
+func NewAPIKeyService(repo repository.APIKeyRepository, logger *slog.Logger) *APIKeyService {
+	return &APIKeyService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateAPIKey issues a new API key. The returned key value is not stored and cannot be retrieved again.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, req *model.CreateAPIKeyRequest) (_ *model.CreatedAPIKey, err error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(now) {
+		return nil, ValidationError(map[string]string{"ExpiresAt": "Must be in the future"})
+	}
+
+	secret, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	value := apiKeyPrefix + secret
+
+	key := model.APIKey{
+		ID:        newTokenID(),
+		Name:      req.Name,
+		KeyHash:   hashToken(value),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		CreatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, &key); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "API key issued", "api_key_id", key.ID, "name", key.Name, "scopes", key.Scopes)
+	return &model.CreatedAPIKey{APIKey: key, Key: value}, nil
+}
+
+// ListAPIKeys returns every issued API key, including revoked and expired ones
+func (s *APIKeyService) ListAPIKeys(ctx context.Context) (_ []model.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.ListAPIKeys")
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.List(ctx)
+}
+
+// RevokeAPIKey revokes an API key by ID
+func (s *APIKeyService) RevokeAPIKey(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.repo.Revoke(ctx, id, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return NotFoundError("active API key with ID %s not found", id)
+		}
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "API key revoked", "api_key_id", id)
+	return nil
+}
+
+// VerifyAPIKey returns the API key record for a key value, or an
+// ErrUnauthorized error when the key is unknown, revoked or expired
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, value string) (_ *model.APIKey, err error) {
+	ctx, span := tracer.Start(ctx, "APIKeyService.VerifyAPIKey")
+	defer func() { endSpan(span, err) }()
+
+	key, err := s.repo.GetByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, UnauthorizedError("Invalid API key")
+		}
+		return nil, err
+	}
+
+	if key.RevokedAt != nil {
+		return nil, UnauthorizedError("API key has been revoked")
+	}
+	if key.ExpiresAt != nil && !time.Now().Before(*key.ExpiresAt) {
+		return nil, UnauthorizedError("API key has expired")
+	}
+
+	return key, nil
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	s := NewAPIKeyService(repository.NewMemoryAPIKeyRepository(), slog.New(slog.DiscardHandler))
+
+	created, err := s.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{Name: "nightly-sync", Scopes: []string{"users:list"}})
+	require.NoError(t, err)
+	assert.Contains(t, created.Key, apiKeyPrefix)
+
+	key, err := s.VerifyAPIKey(context.Background(), created.Key)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, key.ID)
+	assert.Equal(t, []string{"users:list"}, key.Scopes)
+
+	_, err = s.VerifyAPIKey(context.Background(), "ak_unknown")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	require.NoError(t, s.RevokeAPIKey(context.Background(), created.ID))
+	_, err = s.VerifyAPIKey(context.Background(), created.Key)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	assert.ErrorIs(t, s.RevokeAPIKey(context.Background(), created.ID), ErrNotFound)
+
+	keys, err := s.ListAPIKeys(context.Background())
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+}
+
+func TestAPIKeyExpiry(t *testing.T) {
+	repo := repository.NewMemoryAPIKeyRepository()
+	s := NewAPIKeyService(repo, slog.New(slog.DiscardHandler))
+
+	past := time.Now().Add(-time.Minute)
+	_, err := s.CreateAPIKey(context.Background(), &model.CreateAPIKeyRequest{Name: "old", Scopes: []string{"users:list"}, ExpiresAt: &past})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// Keys stored with an expiry in the past are rejected
+	require.NoError(t, repo.Create(context.Background(), &model.APIKey{ID: "expired", KeyHash: hashToken("ak_expired"), ExpiresAt: &past}))
+	_, err = s.VerifyAPIKey(context.Background(), "ak_expired")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// AuthService signs users in with their password and issues access and refresh tokens.
+// Refresh tokens are single use: every refresh revokes the presented token and
+// issues a new one in the same family. Presenting a revoked token is treated as
+// theft and revokes the whole family.
+type AuthService struct {
+	logger     *slog.Logger
+	users      repository.UserRepository
+	tokens     repository.RefreshTokenRepository
+	method     jwt.SigningMethod
+	key        interface{}
+	keyID      string
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// accessClaims are the claims of an issued access token
+type accessClaims struct {
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// NewAuthService creates an auth service signing access tokens with the configured key
+func NewAuthService(users repository.UserRepository, tokens repository.RefreshTokenRepository, cfg config.AuthConfig, logger *slog.Logger) (*AuthService, error) {
+	s := &AuthService{
+		logger:     logger,
+		users:      users,
+		tokens:     tokens,
+		keyID:      cfg.JWT.KeyID,
+		issuer:     cfg.JWT.Issuer,
+		audience:   cfg.JWT.Audience,
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+	}
+
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		s.method = jwt.SigningMethodHS256
+		s.key = []byte(cfg.JWT.Secret)
+	case "RS256":
+		pem, err := os.ReadFile(cfg.JWT.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JWT private key: %w", err)
+		}
+		s.method = jwt.SigningMethodRS256
+		s.key = key
+	default:
+		return nil, fmt.Errorf("unsupported JWT algorithm: %s", cfg.JWT.Algorithm)
+	}
+
+	return s, nil
+}
+
+// Login verifies the user's email and password and starts a new session
+func (s *AuthService) Login(ctx context.Context, req *model.LoginRequest) (_ *model.TokenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Login")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.GetByEmail(ctx, req.Email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	if user == nil || user.PasswordHash == "" || user.DeletedAt != nil {
+		// Spend the same time as a real comparison so unknown emails can't be detected
+		_ = bcrypt.CompareHashAndPassword(dummyHash(), []byte(req.Password))
+		return nil, UnauthorizedError("Invalid email or password")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
+		s.logger.WarnContext(ctx, "login failed: wrong password", "user_id", user.ID)
+		return nil, UnauthorizedError("Invalid email or password")
+	}
+
+	if user.Status != "active" {
+		s.logger.WarnContext(ctx, "login refused: user not active", "user_id", user.ID, "status", user.Status)
+		return nil, UnauthorizedError("User account is %s", user.Status)
+	}
+
+	s.logger.InfoContext(ctx, "user logged in", "user_id", user.ID)
+	return s.issue(ctx, user, newTokenID())
+}
+
+// Refresh exchanges a refresh token for a new token pair, revoking the presented token
+func (s *AuthService) Refresh(ctx context.Context, req *model.RefreshTokenRequest) (_ *model.TokenResponse, err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Refresh")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	token, err := s.findRefreshToken(ctx, req.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil {
+		return nil, s.revokeStolen(ctx, token)
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, UnauthorizedError("Refresh token has expired")
+	}
+
+	// Only one concurrent refresh can revoke the token; the loser is treated as reuse
+	if err := s.tokens.Revoke(ctx, token.ID, now); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, s.revokeStolen(ctx, token)
+		}
+		return nil, err
+	}
+
+	user, err := s.users.Get(ctx, token.UserID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if user == nil || user.DeletedAt != nil || user.Status != "active" {
+		if err := s.tokens.RevokeFamily(ctx, token.FamilyID, now); err != nil {
+			return nil, err
+		}
+		return nil, UnauthorizedError("User account is no longer active")
+	}
+
+	return s.issue(ctx, user, token.FamilyID)
+}
+
+// Logout ends the session the refresh token belongs to. Unknown tokens are ignored.
+func (s *AuthService) Logout(ctx context.Context, req *model.RefreshTokenRequest) (err error) {
+	ctx, span := tracer.Start(ctx, "AuthService.Logout")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return err
+	}
+
+	token, err := s.tokens.GetByHash(ctx, hashToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	return s.tokens.RevokeFamily(ctx, token.FamilyID, time.Now())
+}
+
+// findRefreshToken looks up a refresh token by its value
+func (s *AuthService) findRefreshToken(ctx context.Context, value string) (*model.RefreshToken, error) {
+	token, err := s.tokens.GetByHash(ctx, hashToken(value))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, UnauthorizedError("Invalid refresh token")
+		}
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// revokeStolen revokes the family of a refresh token that was presented after being rotated
+func (s *AuthService) revokeStolen(ctx context.Context, token *model.RefreshToken) error {
+	s.logger.WarnContext(ctx, "refresh token reused; revoking session", "user_id", token.UserID, "family_id", token.FamilyID)
+
+	if err := s.tokens.RevokeFamily(ctx, token.FamilyID, time.Now()); err != nil {
+		return err
+	}
+	return UnauthorizedError("Refresh token has been revoked")
+}
+
+// issue creates an access token and a new refresh token in the given family
+func (s *AuthService) issue(ctx context.Context, user *model.User, familyID string) (*model.TokenResponse, error) {
+	now := time.Now()
+
+	claims := accessClaims{
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        newTokenID(),
+			Subject:   fmt.Sprint(user.ID),
+			Issuer:    s.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+		},
+	}
+	if s.audience != "" {
+		claims.Audience = jwt.ClaimStrings{s.audience}
+	}
+
+	token := jwt.NewWithClaims(s.method, claims)
+	if s.keyID != "" {
+		token.Header["kid"] = s.keyID
+	}
+
+	accessToken, err := token.SignedString(s.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign access token: %w", err)
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.tokens.Create(ctx, &model.RefreshToken{
+		ID:        newTokenID(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: now.Add(s.refreshTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(s.accessTTL.Seconds()),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// hashPassword hashes a password with bcrypt
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// dummyHash returns a bcrypt hash compared against when a login has no user to check
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// randomToken returns a random URL-safe refresh token value
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken returns the stored form of a refresh token value
+func hashToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// newTokenID returns a random identifier for tokens and token families
+func newTokenID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+const testJWTSecret = "test-secret-that-is-at-least-32-bytes"
+
+func newTestAuthService(t *testing.T) (*AuthService, *UserService) {
+	t.Helper()
+
+	users := repository.NewMemoryUserRepository()
+	auth, err := NewAuthService(users, repository.NewMemoryRefreshTokenRepository(), config.AuthConfig{
+		JWT:             config.JWTConfig{Algorithm: "HS256", Secret: testJWTSecret, Issuer: "test"},
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+	}, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	userService := NewUserService(users, "test-secret", slog.New(slog.DiscardHandler))
+	_, err = userService.CreateUser(context.Background(), &model.CreateUserRequest{
+		Email:     "john@example.com",
+		FirstName: "John",
+		LastName:  "Doe",
+		Age:       30,
+		Password:  "correct-horse-battery",
+	})
+	require.NoError(t, err)
+
+	return auth, userService
+}
+
+func TestLogin(t *testing.T) {
+	auth, _ := newTestAuthService(t)
+
+	tokens, err := auth.Login(context.Background(), &model.LoginRequest{Email: "john@example.com", Password: "correct-horse-battery"})
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", tokens.TokenType)
+	assert.Equal(t, 900, tokens.ExpiresIn)
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	claims := &accessClaims{}
+	_, err = jwt.ParseWithClaims(tokens.AccessToken, claims, func(*jwt.Token) (interface{}, error) {
+		return []byte(testJWTSecret), nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, "user", claims.Role)
+	assert.Equal(t, "test", claims.Issuer)
+
+	_, err = auth.Login(context.Background(), &model.LoginRequest{Email: "john@example.com", Password: "wrong-password"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = auth.Login(context.Background(), &model.LoginRequest{Email: "nobody@example.com", Password: "correct-horse-battery"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestLoginRejectsInactiveUser(t *testing.T) {
+	auth, users := newTestAuthService(t)
+
+	status := "suspended"
+	_, err := users.UpdateUser(context.Background(), 1, &model.UpdateUserRequest{Status: &status}, UpdateOptions{AllowStatusChange: true})
+	require.NoError(t, err)
+
+	_, err = auth.Login(context.Background(), &model.LoginRequest{Email: "john@example.com", Password: "correct-horse-battery"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestRefreshRotatesAndDetectsReuse(t *testing.T) {
+	auth, _ := newTestAuthService(t)
+
+	first, err := auth.Login(context.Background(), &model.LoginRequest{Email: "john@example.com", Password: "correct-horse-battery"})
+	require.NoError(t, err)
+
+	second, err := auth.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	// Replaying the rotated token revokes the whole family, including the new token
+	_, err = auth.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: first.RefreshToken})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = auth.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: second.RefreshToken})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = auth.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: "unknown"})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestLogout(t *testing.T) {
+	auth, _ := newTestAuthService(t)
+
+	tokens, err := auth.Login(context.Background(), &model.LoginRequest{Email: "john@example.com", Password: "correct-horse-battery"})
+	require.NoError(t, err)
+
+	require.NoError(t, auth.Logout(context.Background(), &model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken}))
+
+	_, err = auth.Refresh(context.Background(), &model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	// Logging out twice is harmless
+	assert.NoError(t, auth.Logout(context.Background(), &model.RefreshTokenRequest{RefreshToken: tokens.RefreshToken}))
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// errBatchAborted is reported for the items of an atomic batch that were rolled back
+var errBatchAborted = &Error{Kind: ErrAborted, Message: "Not applied because another item of the atomic batch failed"}
+
+// BatchResult is the outcome of one item of a batch
+type BatchResult struct {
+	// User is the created or updated user; it is nil for deletions and failures
+	User *model.User
+	Err  error
+}
+
+// BatchCreateUsers creates several users. Each user is created on its own
+// unless atomic is set, in which case either every user is created or none.
+func (s *UserService) BatchCreateUsers(ctx context.Context, reqs []model.CreateUserRequest, atomic bool) (_ []BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.BatchCreateUsers", batchAttributes(len(reqs), atomic))
+	defer func() { endSpan(span, err) }()
+
+	users, errs, err := prepareUsers(ctx, reqs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.batch(ctx, len(reqs), atomic,
+		func(i int) error {
+			return errs[i]
+		},
+		func(svc *UserService, i int) (*model.User, error) {
+			if errs[i] != nil {
+				return nil, errs[i]
+			}
+			return svc.insertUser(ctx, users[i])
+		},
+	)
+}
+
+// prepareUsers validates the create requests of a batch and hashes their
+// passwords, several at a time, before anything is written. Hashing is by far
+// the slowest part of creating a user, and doing it here keeps it out of the
+// transaction of an atomic batch. The error is only set when ctx is done.
+func prepareUsers(ctx context.Context, reqs []model.CreateUserRequest) ([]*model.User, []error, error) {
+	users := make([]*model.User, len(reqs))
+	errs := make([]error, len(reqs))
+
+	limit := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i := range reqs {
+		select {
+		case limit <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-limit }()
+			users[i], errs[i] = newUser(&reqs[i])
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	return users, errs, nil
+}
+
+// BatchUpdateUsers updates several users like UpdateUser. Each user is updated
+// on its own unless atomic is set, in which case either every user is updated
+// or none.
+func (s *UserService) BatchUpdateUsers(ctx context.Context, items []model.BatchUpdateUser, atomic, allowStatusChange bool) (_ []BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.BatchUpdateUsers", batchAttributes(len(items), atomic))
+	defer func() { endSpan(span, err) }()
+
+	return s.batch(ctx, len(items), atomic,
+		func(i int) error {
+			return validate(&items[i])
+		},
+		func(svc *UserService, i int) (*model.User, error) {
+			return svc.UpdateUser(ctx, items[i].ID, &items[i].UpdateUserRequest, UpdateOptions{
+				ExpectedVersion:   items[i].Version,
+				AllowStatusChange: allowStatusChange,
+			})
+		},
+	)
+}
+
+// BatchDeleteUsers deletes several users. Each user is deleted on its own
+// unless atomic is set, in which case either every user is deleted or none.
+func (s *UserService) BatchDeleteUsers(ctx context.Context, ids []int, atomic bool) (_ []BatchResult, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.BatchDeleteUsers", batchAttributes(len(ids), atomic))
+	defer func() { endSpan(span, err) }()
+
+	return s.batch(ctx, len(ids), atomic,
+		func(i int) error {
+			if ids[i] <= 0 {
+				return ValidationError(map[string]string{"ID": "Must be a positive integer"})
+			}
+			return nil
+		},
+		func(svc *UserService, i int) (*model.User, error) {
+			return nil, svc.DeleteUser(ctx, ids[i])
+		},
+	)
+}
+
+// batch runs apply for every item of a batch and collects the outcomes. In
+// atomic mode every item is checked before anything is written, the writes
+// share one transaction and a single failure rolls back the whole batch;
+// otherwise a failed item does not affect the others. The error is only set
+// when the batch could not be processed at all.
+func (s *UserService) batch(ctx context.Context, size int, atomic bool, check func(i int) error, apply func(svc *UserService, i int) (*model.User, error)) ([]BatchResult, error) {
+	results := make([]BatchResult, size)
+
+	if !atomic {
+		for i := range results {
+			results[i].User, results[i].Err = apply(s, i)
+		}
+		return results, nil
+	}
+
+	failed := false
+	for i := range results {
+		if err := check(i); err != nil {
+			results[i].Err = err
+			failed = true
+		}
+	}
+
+	if !failed {
+		err := s.repo.Atomic(ctx, func(repo repository.UserRepository) error {
+			tx := s.withRepo(repo)
+			for i := range results {
+				user, err := apply(tx, i)
+				if err != nil {
+					results[i].Err = err
+					return err
+				}
+				results[i].User = user
+			}
+			return nil
+		})
+		if err != nil {
+			failed = true
+			if !hasItemError(results) {
+				return nil, err
+			}
+		}
+	}
+
+	if failed {
+		for i := range results {
+			if results[i].Err == nil {
+				results[i] = BatchResult{Err: errBatchAborted}
+			}
+		}
+		s.logger.WarnContext(ctx, "atomic batch rolled back", "size", size)
+	}
+
+	return results, nil
+}
+
+// withRepo returns a copy of the service that stores users in repo
+func (s *UserService) withRepo(repo repository.UserRepository) *UserService {
+	svc := *s
+	svc.repo = repo
+	return &svc
+}
+
+// hasItemError reports whether any item of a batch failed
+func hasItemError(results []BatchResult) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// batchAttributes describes a batch on its span
+func batchAttributes(size int, atomic bool) trace.SpanStartOption {
+	return trace.WithAttributes(
+		attribute.Int("batch.size", size),
+		attribute.Bool("batch.atomic", atomic),
+	)
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/your-org/your-project/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newCreateRequests(emails ...string) []model.CreateUserRequest {
+	reqs := make([]model.CreateUserRequest, 0, len(emails))
+	for _, email := range emails {
+		reqs = append(reqs, model.CreateUserRequest{Email: email, FirstName: "John", LastName: "Doe", Age: 30})
+	}
+	return reqs
+}
+
+func TestBatchCreateUsers(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+
+	reqs := newCreateRequests("a@example.com", "user0@example.com", "not-an-email", "b@example.com")
+	results, err := s.BatchCreateUsers(ctx, reqs, false)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "a@example.com", results[0].User.Email)
+	assert.ErrorIs(t, results[1].Err, ErrConflict)
+	assert.ErrorIs(t, results[2].Err, ErrValidation)
+	assert.NoError(t, results[3].Err)
+
+	page, err := s.ListUsers(ctx, &model.UserListQuery{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Meta.Total)
+}
+
+func TestBatchCreateUsersAtomic(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+
+	// Invalid items are reported together and nothing is written
+	results, err := s.BatchCreateUsers(ctx, newCreateRequests("a@example.com", "bad", "also-bad"), true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrAborted)
+	assert.ErrorIs(t, results[1].Err, ErrValidation)
+	assert.ErrorIs(t, results[2].Err, ErrValidation)
+
+	// A failed write rolls back the items written before it
+	results, err = s.BatchCreateUsers(ctx, newCreateRequests("a@example.com", "user0@example.com", "b@example.com"), true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrAborted)
+	assert.Nil(t, results[0].User)
+	assert.ErrorIs(t, results[1].Err, ErrConflict)
+	assert.ErrorIs(t, results[2].Err, ErrAborted)
+
+	page, err := s.ListUsers(ctx, &model.UserListQuery{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Meta.Total)
+
+	results, err = s.BatchCreateUsers(ctx, newCreateRequests("a@example.com", "b@example.com"), true)
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.NoError(t, result.Err)
+		assert.NotZero(t, result.User.ID)
+	}
+}
+
+func TestBatchCreateUsersHashesPasswords(t *testing.T) {
+	s := newTestService(t, 0)
+	ctx := context.Background()
+
+	reqs := newCreateRequests("a@example.com", "b@example.com", "c@example.com", "d@example.com")
+	for i := range reqs {
+		reqs[i].Password = fmt.Sprintf("password-%d", i)
+	}
+	reqs[3].Password = "short"
+
+	// A password failing validation aborts an atomic batch before anything is written
+	results, err := s.BatchCreateUsers(ctx, reqs, true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrAborted)
+	assert.ErrorIs(t, results[3].Err, ErrValidation)
+
+	results, err = s.BatchCreateUsers(ctx, reqs[:3], true)
+	require.NoError(t, err)
+	for i, result := range results {
+		require.NoError(t, result.Err)
+		user, err := s.GetUser(ctx, result.User.ID, false)
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(reqs[i].Password)))
+	}
+}
+
+func TestBatchUpdateUsers(t *testing.T) {
+	s := newTestService(t, 2)
+	ctx := context.Background()
+	name := "Jane"
+	status := "suspended"
+
+	items := []model.BatchUpdateUser{
+		{ID: 1, UpdateUserRequest: model.UpdateUserRequest{FirstName: &name}},
+		{ID: 2, Version: 5, UpdateUserRequest: model.UpdateUserRequest{FirstName: &name}},
+		{ID: 2, UpdateUserRequest: model.UpdateUserRequest{Status: &status}},
+		{ID: 42, UpdateUserRequest: model.UpdateUserRequest{FirstName: &name}},
+	}
+	results, err := s.BatchUpdateUsers(ctx, items, false, false)
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "Jane", results[0].User.FirstName)
+	assert.ErrorIs(t, results[1].Err, ErrPreconditionFailed)
+	assert.ErrorIs(t, results[2].Err, ErrForbidden)
+	assert.ErrorIs(t, results[3].Err, ErrNotFound)
+
+	results, err = s.BatchUpdateUsers(ctx, items[:1], true, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, results[0].User.Version)
+
+	// Items are validated up front in atomic mode
+	results, err = s.BatchUpdateUsers(ctx, []model.BatchUpdateUser{{UpdateUserRequest: model.UpdateUserRequest{FirstName: &name}}}, true, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrValidation)
+}
+
+func TestBatchDeleteUsers(t *testing.T) {
+	s := newTestService(t, 2)
+	ctx := context.Background()
+
+	results, err := s.BatchDeleteUsers(ctx, []int{1, 2, 42}, true)
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, ErrAborted)
+	assert.ErrorIs(t, results[1].Err, ErrAborted)
+	assert.ErrorIs(t, results[2].Err, ErrNotFound)
+
+	_, err = s.GetUser(ctx, 1, false)
+	assert.NoError(t, err)
+
+	results, err = s.BatchDeleteUsers(ctx, []int{1, 0, 1}, false)
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, ErrNotFound)
+	assert.ErrorIs(t, results[2].Err, ErrNotFound)
+
+	_, err = s.GetUser(ctx, 1, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestBatchCancelledContext(t *testing.T) {
+	s := newTestService(t, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.BatchCreateUsers(ctx, newCreateRequests("a@example.com"), true)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// errInvalidCursor is returned when a cursor is malformed or its signature does not match
+var errInvalidCursor = errors.New("invalid cursor")
+
+// cursorToken is the signed payload of a pagination cursor. The anchor holds
+// the ID and sorted fields of the user at the edge of the previous page.
+type cursorToken struct {
+	After  map[string]json.RawMessage `json:"a,omitempty"`
+	Before map[string]json.RawMessage `json:"b,omitempty"`
+	// Query fingerprints the filter and sort the cursor was issued for
+	Query string `json:"q"`
+}
+
+// cursorCodec encodes pagination cursors as opaque, HMAC-signed tokens
+type cursorCodec struct {
+	key []byte
+}
+
+// newCursorCodec creates a codec signing with secret, or with a random key when secret is empty
+func newCursorCodec(secret string) *cursorCodec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic("failed to generate cursor secret: " + err.Error())
+		}
+	}
+
+	return &cursorCodec{key: key}
+}
+
+// encode creates a cursor anchored at user, keeping only the fields the list is sorted by
+func (c *cursorCodec) encode(user *model.User, opts *repository.ListOptions, before bool) string {
+	fields, _ := json.Marshal(user)
+
+	var all map[string]json.RawMessage
+	_ = json.Unmarshal(fields, &all)
+
+	anchor := map[string]json.RawMessage{"id": all["id"]}
+	for _, key := range opts.Sort {
+		anchor[key.Field] = all[key.Field]
+	}
+
+	token := cursorToken{Query: fingerprint(opts)}
+	if before {
+		token.Before = anchor
+	} else {
+		token.After = anchor
+	}
+
+	payload, _ := json.Marshal(token)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded))
+}
+
+// decode verifies a cursor and anchors opts at its position. The cursor must
+// have been issued for the same filter and sort as opts.
+func (c *cursorCodec) decode(cursor string, opts *repository.ListOptions) error {
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return errInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(encoded)) {
+		return errInvalidCursor
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return errInvalidCursor
+	}
+
+	var token cursorToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return errInvalidCursor
+	}
+
+	// Exactly one direction must be set and the query must not have changed
+	if (token.After == nil) == (token.Before == nil) || token.Query != fingerprint(opts) {
+		return errInvalidCursor
+	}
+
+	anchor := token.After
+	if token.Before != nil {
+		anchor = token.Before
+	}
+
+	raw, _ := json.Marshal(anchor)
+	var user model.User
+	if err := json.Unmarshal(raw, &user); err != nil {
+		return errInvalidCursor
+	}
+
+	if token.Before != nil {
+		opts.Before = &user
+	} else {
+		opts.After = &user
+	}
+
+	return nil
+}
+
+// sign computes the HMAC-SHA256 of the encoded payload
+func (c *cursorCodec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// fingerprint summarizes the filter and sort of a listing
+func fingerprint(opts *repository.ListOptions) string {
+	query, _ := json.Marshal(struct {
+		Filter repository.UserFilter
+		Sort   []repository.SortField
+	}{opts.Filter, opts.Sort})
+
+	sum := sha256.Sum256(query)
+	return base64.RawURLEncoding.EncodeToString(sum[:8])
+}
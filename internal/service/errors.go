@@ -0,0 +1,79 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// Error kinds returned by services. Use errors.Is to classify a service error.
+var (
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrValidation   = errors.New("validation failed")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	// ErrPreconditionFailed reports that a conditional request targeted a stale version
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrAborted reports an item of an atomic batch that was not applied because another item failed
+	ErrAborted = errors.New("aborted")
+)
+
+// Error is a domain error with a client-facing message
+type Error struct {
+	// Kind is one of the Err* sentinels and decides how the error is reported
+	Kind error
+	// Message describes the error to API clients
+	Message string
+	// Fields holds per-field messages for validation errors
+	Fields map[string]string
+}
+
+// Error returns the client-facing message
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap exposes the error kind to errors.Is
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// NotFoundError creates an ErrNotFound error with a formatted message
+func NotFoundError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// ConflictError creates an ErrConflict error with a formatted message
+func ConflictError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// ValidationError creates an ErrValidation error from the given field messages
+func ValidationError(fields map[string]string) error {
+	return &Error{Kind: ErrValidation, Message: "Validation failed", Fields: fields}
+}
+
+// UnauthorizedError creates an ErrUnauthorized error with a formatted message
+func UnauthorizedError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrUnauthorized, Message: fmt.Sprintf(format, args...)}
+}
+
+// ForbiddenError creates an ErrForbidden error with a formatted message
+func ForbiddenError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrForbidden, Message: fmt.Sprintf(format, args...)}
+}
+
+// PreconditionFailedError creates an ErrPreconditionFailed error with a formatted message
+func PreconditionFailedError(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPreconditionFailed, Message: fmt.Sprintf(format, args...)}
+}
+
+// validate checks the struct's validate tags, returning a ValidationError on failure
+func validate(s interface{}) error {
+	if err := model.ValidateStruct(s); err != nil {
+		return ValidationError(model.GetValidationErrors(err))
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// UserServiceOption configures optional user service behaviour
+type UserServiceOption func(*UserService)
+
+// WithEvents records a domain event in the outbox for every user that is
+// created, updated, deleted or restored
+func WithEvents() UserServiceOption {
+	return func(s *UserService) {
+		s.events = true
+	}
+}
+
+// write runs fn against the repository. When events are recorded, the event
+// fn returns is stored in the same transaction as the change; a nil event
+// records nothing.
+func (s *UserService) write(ctx context.Context, fn func(repo repository.UserRepository) (*model.Event, error)) error {
+	if !s.events {
+		_, err := fn(s.repo)
+		return err
+	}
+
+	return s.repo.Atomic(ctx, func(repo repository.UserRepository) error {
+		event, err := fn(repo)
+		if err != nil || event == nil {
+			return err
+		}
+		return repo.AppendEvents(ctx, event)
+	})
+}
+
+// newEvent wraps a typed event about a user in its outbox envelope
+func newEvent(userID int, payload model.DomainEvent) (*model.Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Event{
+		ID:         newTokenID(),
+		Type:       payload.EventType(),
+		UserID:     userID,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}, nil
+}
+
+// userEventFields lists the user fields whose changes UserUpdated events report,
+// by their JSON names
+var userEventFields = []struct {
+	name  string
+	value func(user *model.User) interface{}
+}{
+	{"email", func(user *model.User) interface{} { return user.Email }},
+	{"first_name", func(user *model.User) interface{} { return user.FirstName }},
+	{"last_name", func(user *model.User) interface{} { return user.LastName }},
+	{"age", func(user *model.User) interface{} { return user.Age }},
+	{"phone", func(user *model.User) interface{} { return user.Phone }},
+	{"status", func(user *model.User) interface{} { return user.Status }},
+	{"role", func(user *model.User) interface{} { return user.Role }},
+	{"deleted_at", func(user *model.User) interface{} {
+		if user.DeletedAt == nil {
+			return nil
+		}
+		return user.DeletedAt.UTC()
+	}},
+}
+
+// diffUsers returns the fields that differ between two versions of a user
+func diffUsers(before, after *model.User) map[string]model.FieldChange {
+	changes := make(map[string]model.FieldChange)
+	for _, field := range userEventFields {
+		from, to := field.value(before), field.value(after)
+		if fromTime, ok := from.(time.Time); ok {
+			if toTime, ok := to.(time.Time); ok && fromTime.Equal(toTime) {
+				continue
+			}
+		} else if from == to {
+			continue
+		}
+		changes[field.name] = model.FieldChange{From: from, To: to}
+	}
+	return changes
+}
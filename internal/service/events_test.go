@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserEvents(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	s := NewUserService(repo, "test-secret", slog.New(slog.DiscardHandler), WithEvents())
+	ctx := context.Background()
+
+	user, err := s.CreateUser(ctx, &model.CreateUserRequest{Email: "john@example.com", FirstName: "John", LastName: "Doe", Age: 30})
+	require.NoError(t, err)
+
+	// An update that changes nothing records no event
+	name := "Jane"
+	age := 30
+	_, err = s.UpdateUser(ctx, user.ID, &model.UpdateUserRequest{FirstName: &name, Age: &age}, UpdateOptions{})
+	require.NoError(t, err)
+	_, err = s.UpdateUser(ctx, user.ID, &model.UpdateUserRequest{FirstName: &name}, UpdateOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteUser(ctx, user.ID))
+	_, err = s.RestoreUser(ctx, user.ID)
+	require.NoError(t, err)
+
+	// A failed write records nothing
+	_, err = s.CreateUser(ctx, &model.CreateUserRequest{Email: "john@example.com", FirstName: "John", LastName: "Doe", Age: 30})
+	require.ErrorIs(t, err, ErrConflict)
+
+	events, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	types := make([]model.EventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type
+		assert.Equal(t, user.ID, event.UserID)
+		assert.NotEmpty(t, event.ID)
+	}
+	assert.Equal(t, []model.EventType{model.EventUserCreated, model.EventUserUpdated, model.EventUserDeleted, model.EventUserUpdated}, types)
+
+	var created model.UserCreated
+	require.NoError(t, json.Unmarshal(events[0].Data, &created))
+	assert.Equal(t, "john@example.com", created.User.Email)
+
+	var updated model.UserUpdated
+	require.NoError(t, json.Unmarshal(events[1].Data, &updated))
+	assert.Equal(t, "Jane", updated.User.FirstName)
+	assert.Equal(t, map[string]model.FieldChange{"first_name": {From: "John", To: "Jane"}}, updated.Changes)
+
+	var deleted model.UserDeleted
+	require.NoError(t, json.Unmarshal(events[2].Data, &deleted))
+	assert.Equal(t, user.ID, deleted.UserID)
+
+	var restored model.UserUpdated
+	require.NoError(t, json.Unmarshal(events[3].Data, &restored))
+	require.Contains(t, restored.Changes, "deleted_at")
+	assert.NotNil(t, restored.Changes["deleted_at"].From)
+	assert.Nil(t, restored.Changes["deleted_at"].To)
+}
+
+func TestUserEventsDisabled(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	s := NewUserService(repo, "test-secret", slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	_, err := s.CreateUser(ctx, &model.CreateUserRequest{Email: "john@example.com", FirstName: "John", LastName: "Doe", Age: 30})
+	require.NoError(t, err)
+
+	events, err := repo.Pending(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
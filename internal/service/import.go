@@ -0,0 +1,470 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// maxJobErrors bounds how many failed items a job lists; all of them are counted
+const maxJobErrors = 1000
+
+// importProgressEvery is how many items are processed between progress updates
+const importProgressEvery = 100
+
+// ImportService imports users from uploaded files as background jobs and
+// tracks the progress of each import
+type ImportService struct {
+	users  *UserService
+	jobs   repository.JobRepository
+	queue  *queue.Queue
+	logger *slog.Logger
+}
+
+// importTask is the queue payload of an accepted import
+type importTask struct {
+	JobID  string             `json:"job_id"`
+	Format model.ImportFormat `json:"format"`
+	Data   []byte             `json:"data"`
+	DryRun bool               `json:"dry_run"`
+}
+
+// NewImportService creates a new import service that creates users through
+// users, runs imports on q and records their progress in jobs
+func NewImportService(users *UserService, jobs repository.JobRepository, q *queue.Queue, logger *slog.Logger) *ImportService {
+	s := &ImportService{
+		users:  users,
+		jobs:   jobs,
+		queue:  q,
+		logger: logger,
+	}
+	q.Handle(model.JobTypeUserImport, s.runImport)
+	return s
+}
+
+// StartImport checks the file's format and header and queues the import of
+// its users. A dry run validates every user without creating any. The
+// returned job reports the import's progress.
+func (s *ImportService) StartImport(ctx context.Context, format model.ImportFormat, data []byte, dryRun bool) (_ *model.Job, err error) {
+	ctx, span := tracer.Start(ctx, "ImportService.StartImport", trace.WithAttributes(
+		attribute.String("import.format", string(format)),
+		attribute.Bool("import.dry_run", dryRun),
+	))
+	defer func() { endSpan(span, err) }()
+
+	if _, err := newImportReader(format, data); err != nil {
+		return nil, err
+	}
+
+	job := &model.Job{
+		ID:        newTokenID(),
+		Type:      model.JobTypeUserImport,
+		Status:    model.JobQueued,
+		DryRun:    dryRun,
+		CreatedAt: time.Now(),
+	}
+	if err := s.jobs.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	// Rows that fail are recorded on the job, so a failed import is not worth
+	// retrying. An import cut short by shutdown is released without using up
+	// its attempt and resumes where it stopped.
+	task := importTask{JobID: job.ID, Format: format, Data: data, DryRun: dryRun}
+	if _, err := s.queue.Enqueue(ctx, model.JobTypeUserImport, task, queue.MaxAttempts(1)); err != nil {
+		s.finish(context.WithoutCancel(ctx), job, "Import could not be queued")
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "user import queued", "job_id", job.ID, "format", format, "dry_run", dryRun)
+	return job, nil
+}
+
+// GetJob retrieves an import job by ID
+func (s *ImportService) GetJob(ctx context.Context, id string) (_ *model.Job, err error) {
+	ctx, span := tracer.Start(ctx, "ImportService.GetJob")
+	defer func() { endSpan(span, err) }()
+
+	job, err := s.jobs.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NotFoundError("job with ID %s not found", id)
+		}
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// runImport is the queue handler for user imports
+func (s *ImportService) runImport(ctx context.Context, queued *model.QueueJob) error {
+	var task importTask
+	if err := json.Unmarshal(queued.Payload, &task); err != nil {
+		return queue.Permanent(err)
+	}
+
+	return s.process(ctx, task)
+}
+
+// process runs one import, saving its progress as it goes. An import that
+// already processed items, because shutdown interrupted it or its server
+// stopped responding, resumes after the last item it saved.
+func (s *ImportService) process(ctx context.Context, task importTask) error {
+	// Progress is saved even once the import is being interrupted
+	save := context.WithoutCancel(ctx)
+
+	job, err := s.jobs.Get(save, task.JobID)
+	if err != nil {
+		return fmt.Errorf("loading import job %s: %w", task.JobID, err)
+	}
+
+	if job.StartedAt == nil {
+		started := time.Now()
+		job.StartedAt = &started
+	}
+	job.Status = model.JobRunning
+	skip := job.Processed
+	job.Total, err = countImportItems(task.Format, task.Data)
+	if err != nil {
+		s.finish(save, job, err.Error())
+		return nil
+	}
+	s.saveProgress(save, job)
+
+	reader, err := newImportReader(task.Format, task.Data)
+	if err != nil {
+		s.finish(save, job, err.Error())
+		return nil
+	}
+
+	// Emails seen so far, to catch duplicates within a dry run
+	seen := make(map[string]int)
+	for {
+		line, req, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if skip > 0 {
+			// Done by an earlier run; a dry run still needs its emails
+			if err == nil {
+				if _, ok := seen[req.Email]; !ok {
+					seen[req.Email] = line
+				}
+			}
+			skip--
+			continue
+		}
+		if ctx.Err() != nil {
+			return s.interrupt(save, job, ctx.Err())
+		}
+
+		if err == nil {
+			err = s.importUser(ctx, line, req, task.DryRun, seen)
+			if err != nil && ctx.Err() != nil {
+				// The item did not fail on its own, so the next run does it again
+				return s.interrupt(save, job, ctx.Err())
+			}
+		}
+
+		job.Processed++
+		if err != nil {
+			job.Failed++
+			s.recordError(ctx, job, line, err)
+		} else {
+			job.Succeeded++
+		}
+
+		if job.Processed%importProgressEvery == 0 {
+			s.saveProgress(save, job)
+		}
+	}
+
+	s.finish(save, job, "")
+	s.logger.InfoContext(ctx, "user import finished",
+		"job_id", job.ID, "dry_run", job.DryRun, "succeeded", job.Succeeded, "failed", job.Failed)
+	return nil
+}
+
+// interrupt stops an import whose context ended. An import that ran out of
+// time fails; one cut short by shutdown is saved as queued and err is
+// returned, so the queue runs it again without using up its attempt.
+func (s *ImportService) interrupt(ctx context.Context, job *model.Job, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		s.finish(ctx, job, "Import did not finish within the job timeout")
+		return nil
+	}
+
+	job.Status = model.JobQueued
+	s.saveProgress(ctx, job)
+	s.logger.InfoContext(ctx, "user import interrupted and will resume", "job_id", job.ID, "processed", job.Processed)
+	return err
+}
+
+// importUser creates one user, or only checks that it could be created in a dry run
+func (s *ImportService) importUser(ctx context.Context, line int, req *model.CreateUserRequest, dryRun bool, seen map[string]int) error {
+	if !dryRun {
+		_, err := s.users.CreateUser(ctx, req)
+		return err
+	}
+
+	if err := validate(req); err != nil {
+		return err
+	}
+
+	if first, ok := seen[req.Email]; ok {
+		return ConflictError("email %s is already used on line %d", req.Email, first)
+	}
+	seen[req.Email] = line
+
+	return s.users.CheckEmailAvailable(ctx, req.Email)
+}
+
+// recordError adds a failed item to the job's errors while there is room
+func (s *ImportService) recordError(ctx context.Context, job *model.Job, line int, err error) {
+	if len(job.Errors) >= maxJobErrors {
+		return
+	}
+
+	itemErr := model.JobItemError{Line: line, Error: err.Error()}
+
+	var serviceErr *Error
+	var parseErr *importItemError
+	switch {
+	case errors.As(err, &serviceErr):
+		itemErr.Fields = serviceErr.Fields
+	case errors.As(err, &parseErr):
+		itemErr.Fields = parseErr.fields
+	default:
+		s.logger.ErrorContext(ctx, "failed to import user", "job_id", job.ID, "line", line, "error", err)
+		itemErr.Error = "Internal server error"
+	}
+
+	job.Errors = append(job.Errors, itemErr)
+}
+
+// finish records the end of a job; a non-empty reason marks it as failed
+func (s *ImportService) finish(ctx context.Context, job *model.Job, reason string) {
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.Status = model.JobSucceeded
+	if reason != "" {
+		job.Status = model.JobFailed
+		job.Error = reason
+	}
+	s.saveProgress(ctx, job)
+}
+
+// saveProgress stores the job, logging failures so a storage hiccup does not stop the import
+func (s *ImportService) saveProgress(ctx context.Context, job *model.Job) {
+	if err := s.jobs.Update(ctx, job); err != nil {
+		s.logger.ErrorContext(ctx, "failed to save import job", "job_id", job.ID, "error", err)
+	}
+}
+
+// importItemError is a malformed import item. It fails the item, not the import.
+type importItemError struct {
+	message string
+	fields  map[string]string
+}
+
+// Error returns the description of the problem
+func (e *importItemError) Error() string {
+	return e.message
+}
+
+// importReader reads the users of an import one at a time
+type importReader interface {
+	// Next returns the next user and the line it starts on. A malformed item
+	// is reported as an *importItemError and reading may continue; io.EOF
+	// marks the end of the input.
+	Next() (line int, req *model.CreateUserRequest, err error)
+}
+
+// newImportReader creates a reader for data in the given format, checking the CSV header
+func newImportReader(format model.ImportFormat, data []byte) (importReader, error) {
+	// Spreadsheet programs often start UTF-8 files with a byte order mark
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch format {
+	case model.ImportCSV:
+		return newCSVImportReader(data)
+	case model.ImportNDJSON:
+		return &ndjsonImportReader{r: bufio.NewReader(bytes.NewReader(data))}, nil
+	default:
+		return nil, ValidationError(map[string]string{"Format": "Must be one of: csv ndjson"})
+	}
+}
+
+// countImportItems returns the number of items in data
+func countImportItems(format model.ImportFormat, data []byte) (int, error) {
+	reader, err := newImportReader(format, data)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		_, _, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		count++
+	}
+}
+
+// importColumn is a CSV column an import understands
+type importColumn struct {
+	// field is the CreateUserRequest field the column fills, as named in validation errors
+	field string
+	set   func(req *model.CreateUserRequest, value string) error
+}
+
+// importColumns maps CSV header names to the columns an import understands
+var importColumns = map[string]importColumn{
+	"email":      {field: "Email", set: func(req *model.CreateUserRequest, v string) error { req.Email = v; return nil }},
+	"first_name": {field: "FirstName", set: func(req *model.CreateUserRequest, v string) error { req.FirstName = v; return nil }},
+	"last_name":  {field: "LastName", set: func(req *model.CreateUserRequest, v string) error { req.LastName = v; return nil }},
+	"phone":      {field: "Phone", set: func(req *model.CreateUserRequest, v string) error { req.Phone = v; return nil }},
+	"password":   {field: "Password", set: func(req *model.CreateUserRequest, v string) error { req.Password = v; return nil }},
+	"age": {field: "Age", set: func(req *model.CreateUserRequest, v string) error {
+		if v == "" {
+			return nil
+		}
+		age, err := strconv.Atoi(v)
+		if err != nil {
+			return errors.New("Must be a whole number")
+		}
+		req.Age = age
+		return nil
+	}},
+}
+
+// exportOnlyImportColumns are the columns of a user export that describe the
+// stored user rather than a new one. They are ignored, so an export can be
+// imported again.
+var exportOnlyImportColumns = map[string]bool{
+	"id": true, "status": true, "role": true, "version": true,
+	"created_at": true, "updated_at": true, "deleted_at": true,
+}
+
+// requiredImportColumns lists the CSV columns every import must have
+var requiredImportColumns = []string{"email", "first_name", "last_name", "age"}
+
+// csvImportReader reads users from CSV rows below a header row naming the columns
+type csvImportReader struct {
+	r       *csv.Reader
+	columns []importColumn
+}
+
+// newCSVImportReader reads the header row and maps it to import columns
+func newCSVImportReader(data []byte) (*csvImportReader, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ValidationError(map[string]string{"File": "Must start with a header row"})
+		}
+		return nil, ValidationError(map[string]string{"File": "Invalid header row: " + err.Error()})
+	}
+
+	columns := make([]importColumn, len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		column, ok := importColumns[name]
+		if exportOnlyImportColumns[name] {
+			// Left without a setter, the column is skipped
+			ok = true
+		}
+		if !ok || present[name] {
+			return nil, ValidationError(map[string]string{"File": fmt.Sprintf("Unknown or repeated column %q", name)})
+		}
+		columns[i] = column
+		present[name] = true
+	}
+	for _, name := range requiredImportColumns {
+		if !present[name] {
+			return nil, ValidationError(map[string]string{"File": fmt.Sprintf("Missing column %q", name)})
+		}
+	}
+
+	return &csvImportReader{r: r, columns: columns}, nil
+}
+
+// Next reads the next row
+func (r *csvImportReader) Next() (int, *model.CreateUserRequest, error) {
+	record, err := r.r.Read()
+	if err != nil {
+		// A malformed row has no fields, so its line comes from the parse error
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, nil, &importItemError{message: "Invalid CSV row: " + parseErr.Err.Error()}
+		}
+		return 0, nil, err
+	}
+	line, _ := r.r.FieldPos(0)
+
+	var req model.CreateUserRequest
+	fields := make(map[string]string)
+	for i, value := range record {
+		if r.columns[i].set == nil {
+			continue
+		}
+		if err := r.columns[i].set(&req, strings.TrimSpace(value)); err != nil {
+			fields[r.columns[i].field] = err.Error()
+		}
+	}
+	if len(fields) > 0 {
+		return line, nil, &importItemError{message: "Validation failed", fields: fields}
+	}
+
+	return line, &req, nil
+}
+
+// ndjsonImportReader reads users from one JSON object per line, skipping blank lines
+type ndjsonImportReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+// Next reads the next non-blank line
+func (r *ndjsonImportReader) Next() (int, *model.CreateUserRequest, error) {
+	for {
+		data, err := r.r.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			return r.line, nil, err
+		}
+		r.line++
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		var req model.CreateUserRequest
+		if err := decoder.Decode(&req); err != nil {
+			return r.line, nil, &importItemError{message: "Invalid JSON: " + strings.TrimPrefix(err.Error(), "json: ")}
+		}
+		return r.line, &req, nil
+	}
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestImportService(t *testing.T) (*ImportService, *UserService, *queue.Queue) {
+	t.Helper()
+
+	users := newTestService(t, 1)
+	q := queue.New(repository.NewMemoryQueueRepository(), config.QueueConfig{
+		Workers:         1,
+		PollInterval:    10 * time.Millisecond,
+		MaxAttempts:     1,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+		JobTimeout:      time.Minute,
+	}, slog.New(slog.DiscardHandler))
+	s := NewImportService(users, repository.NewMemoryJobRepository(), q, slog.New(slog.DiscardHandler))
+	return s, users, q
+}
+
+// runImport starts an import, runs the queue until it is done and returns the finished job
+func runImport(t *testing.T, s *ImportService, q *queue.Queue, format model.ImportFormat, data string, dryRun bool) *model.Job {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	job, err := s.StartImport(ctx, format, []byte(data), dryRun)
+	require.NoError(t, err)
+	assert.Equal(t, model.JobQueued, job.Status)
+
+	require.Eventually(t, func() bool {
+		job, err = s.GetJob(ctx, job.ID)
+		return err == nil && job.Done()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestImportUsersCSV(t *testing.T) {
+	s, users, q := newTestImportService(t)
+
+	data := "\xef\xbb\xbfEmail,first_name,last_name,age,phone\n" +
+		"a@example.com,Ann,Lee,31,\n" +
+		"user0@example.com,John,Doe,30,\n" +
+		"not-an-email,Bob,Ray,forty,\n" +
+		"b@example.com,Bea,Kim,22,+15550100\n" +
+		"c@example.com,Cy\n"
+	job := runImport(t, s, q, model.ImportCSV, data, false)
+
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Equal(t, 5, job.Total)
+	assert.Equal(t, 5, job.Processed)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Equal(t, 3, job.Failed)
+	require.Len(t, job.Errors, 3)
+	assert.Equal(t, 3, job.Errors[0].Line)
+	assert.Contains(t, job.Errors[0].Error, "already exists")
+	assert.Equal(t, 4, job.Errors[1].Line)
+	assert.Equal(t, "Must be a whole number", job.Errors[1].Fields["Age"])
+	assert.Equal(t, 6, job.Errors[2].Line)
+	assert.NotNil(t, job.StartedAt)
+	assert.NotNil(t, job.FinishedAt)
+
+	user, err := users.repo.GetByEmail(context.Background(), "b@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "+15550100", user.Phone)
+}
+
+func TestImportUsersMalformedCSV(t *testing.T) {
+	s, _, q := newTestImportService(t)
+
+	// A bare quote in the first field fails only that row
+	data := "email,first_name,last_name,age\n" +
+		"a\"b@example.com,Ann,Lee,31\n" +
+		"b@example.com,Bea,Kim,22\n"
+	job := runImport(t, s, q, model.ImportCSV, data, false)
+
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Equal(t, 2, job.Total)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+	require.Len(t, job.Errors, 1)
+	assert.Equal(t, 2, job.Errors[0].Line)
+	assert.Contains(t, job.Errors[0].Error, "Invalid CSV row")
+}
+
+func TestImportUsersResumesAfterShutdown(t *testing.T) {
+	s, users, _ := newTestImportService(t)
+	ctx := context.Background()
+
+	data := "email,first_name,last_name,age\n" +
+		"a@example.com,Ann,Lee,31\n" +
+		"b@example.com,Bea,Kim,22\n"
+	task := importTask{JobID: "import-1", Format: model.ImportCSV, Data: []byte(data)}
+	require.NoError(t, s.jobs.Create(ctx, &model.Job{ID: "import-1", Type: model.JobTypeUserImport, Status: model.JobQueued, CreatedAt: time.Now()}))
+
+	// Shutdown hands the import back to the queue instead of failing it
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, s.process(cancelled, task), context.Canceled)
+	job, err := s.GetJob(ctx, "import-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.JobQueued, job.Status)
+
+	// A run interrupted after creating the first user resumes after it
+	_, err = users.CreateUser(ctx, &model.CreateUserRequest{Email: "a@example.com", FirstName: "Ann", LastName: "Lee", Age: 31})
+	require.NoError(t, err)
+	job.Processed, job.Succeeded = 1, 1
+	require.NoError(t, s.jobs.Update(ctx, job))
+
+	require.NoError(t, s.process(ctx, task))
+	job, err = s.GetJob(ctx, "import-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.JobSucceeded, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 2, job.Succeeded)
+	assert.Zero(t, job.Failed)
+	assert.Empty(t, job.Errors)
+}
+
+func TestImportUsersNDJSONDryRun(t *testing.T) {
+	s, users, q := newTestImportService(t)
+
+	data := `{"email":"a@example.com","first_name":"Ann","last_name":"Lee","age":31}` + "\n\n" +
+		`{"email":"a@example.com","first_name":"Ann","last_name":"Lee","age":31}` + "\n" +
+		`{"email":"b@example.com","first_name":"Bea","last_name":"Kim","age":0}` + "\n" +
+		`{"email":"c@example.com","nickname":"cy"}` + "\n" +
+		`{"email":"user0@example.com","first_name":"John","last_name":"Doe","age":30}`
+	job := runImport(t, s, q, model.ImportNDJSON, data, true)
+
+	assert.True(t, job.DryRun)
+	assert.Equal(t, 5, job.Total)
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 4, job.Failed)
+	require.Len(t, job.Errors, 4)
+	assert.Equal(t, 3, job.Errors[0].Line)
+	assert.Contains(t, job.Errors[0].Error, "line 1")
+	assert.Equal(t, 4, job.Errors[1].Line)
+	assert.Contains(t, job.Errors[1].Fields, "Age")
+	assert.Contains(t, job.Errors[2].Error, "unknown field")
+	assert.Contains(t, job.Errors[3].Error, "already exists")
+
+	// A dry run creates nothing
+	_, err := users.repo.GetByEmail(context.Background(), "a@example.com")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestStartImportRejectsBadInput(t *testing.T) {
+	s, _, _ := newTestImportService(t)
+	ctx := context.Background()
+
+	for name, test := range map[string]struct {
+		format model.ImportFormat
+		data   string
+	}{
+		"unknown format": {format: "xml", data: "<users/>"},
+		"empty csv":      {format: model.ImportCSV, data: ""},
+		"unknown column": {format: model.ImportCSV, data: "email,first_name,last_name,age,nickname\n"},
+		"missing column": {format: model.ImportCSV, data: "email,first_name,last_name\n"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.StartImport(ctx, test.format, []byte(test.data), false)
+			assert.ErrorIs(t, err, ErrValidation)
+		})
+	}
+
+	_, err := s.GetJob(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
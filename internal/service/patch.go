@@ -0,0 +1,60 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/your-org/your-project/internal/model"
+)
+
+// applyPatch applies a patch document to the editable fields of user and
+// decodes the result. Patches that are malformed, cannot be applied or
+// produce fields a user does not have are reported as validation errors;
+// a failed JSON patch test operation is reported as a conflict.
+func applyPatch(user *model.User, format model.PatchFormat, patch []byte) (*model.ReplaceUserRequest, error) {
+	doc, err := json.Marshal(&model.ReplaceUserRequest{
+		Email:     user.Email,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Age:       user.Age,
+		Phone:     user.Phone,
+		Status:    user.Status,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch format {
+	case model.MergePatch:
+		patched, err = jsonpatch.MergePatch(doc, patch)
+	case model.JSONPatch:
+		var ops jsonpatch.Patch
+		ops, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			patched, err = ops.Apply(doc)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported patch format %q", format)
+	}
+	if err != nil {
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, ConflictError("JSON patch test operation failed")
+		}
+		return nil, ValidationError(map[string]string{"Patch": "Cannot apply patch: " + err.Error()})
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	var req model.ReplaceUserRequest
+	if err := decoder.Decode(&req); err != nil {
+		return nil, ValidationError(map[string]string{"Patch": "Patched user is invalid: " + strings.TrimPrefix(err.Error(), "json: ")})
+	}
+
+	return &req, nil
+}
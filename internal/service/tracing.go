@@ -0,0 +1,24 @@
+package service
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates the service spans
+var tracer = otel.Tracer("github.com/your-org/your-project/internal/service")
+
+// endSpan records an unexpected error on the span and ends it. Domain errors
+// such as not found or validation failures are expected outcomes and leave the
+// span status unset.
+func endSpan(span trace.Span, err error) {
+	var serviceErr *Error
+	if err != nil && !errors.As(err, &serviceErr) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
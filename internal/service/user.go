@@ -1,175 +1,566 @@
 package service
 
 import (
-	"fmt"
-	"sync"
+	"context"
+	"errors"
+	"log/slog"
+	"sort"
+	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
 )
 
 // UserService handles business logic for users
 type UserService struct {
-	users  map[int]*model.User
-	nextID int
-	mutex  sync.RWMutex
+	repo    repository.UserRepository
+	cursors *cursorCodec
+	// events records user changes in the outbox
+	events bool
+	logger *slog.Logger
 }
 
-// NewUserService creates a new user service
-func NewUserService() *UserService {
-	return &UserService{
-		users:  make(map[int]*model.User),
-		nextID: 1,
+// NewUserService creates a new user service backed by the given repository.
+// cursorSecret signs pagination cursors; a random secret is used when it is empty.
+func NewUserService(repo repository.UserRepository, cursorSecret string, logger *slog.Logger, opts ...UserServiceOption) *UserService {
+	s := &UserService{
+		repo:    repo,
+		cursors: newCursorCodec(cursorSecret),
+		logger:  logger,
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	return s
 }
 
 // CreateUser creates a new user
-func (s *UserService) CreateUser(req *model.CreateUserRequest) (*model.User, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+func (s *UserService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateUser")
+	defer func() { endSpan(span, err) }()
 
-	// Check if email already exists
-	for _, user := range s.users {
-		if user.Email == req.Email {
-			return nil, fmt.Errorf("user with email %s already exists", req.Email)
-		}
+	user, err := newUser(req)
+	if err != nil {
+		return nil, err
 	}
 
+	return s.insertUser(ctx, user)
+}
+
+// CreateAdmin creates a user with the admin role. It bootstraps the first
+// administrator, who must be able to sign in, so a password is required.
+func (s *UserService) CreateAdmin(ctx context.Context, req *model.CreateUserRequest) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.CreateAdmin")
+	defer func() { endSpan(span, err) }()
+
+	if req.Password == "" {
+		return nil, ValidationError(map[string]string{"Password": "Is required for an administrator"})
+	}
+
+	user, err := newUser(req)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = "admin"
+
+	return s.insertUser(ctx, user)
+}
+
+// newUser validates a create request and builds the user it describes,
+// hashing its password. It is kept apart from insertUser so batches can do
+// this slow part before opening a transaction.
+func newUser(req *model.CreateUserRequest) (*model.User, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
 	user := &model.User{
-		ID:        s.nextID,
 		Email:     req.Email,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		Age:       req.Age,
 		Phone:     req.Phone,
 		Status:    "active",
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Role:      "user",
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	s.users[s.nextID] = user
-	s.nextID++
+	if req.Password != "" {
+		hash, err := hashPassword(req.Password)
+		if err != nil {
+			return nil, err
+		}
+		user.PasswordHash = hash
+	}
 
 	return user, nil
 }
 
-// GetUser retrieves a user by ID
-func (s *UserService) GetUser(id int) (*model.User, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	user, exists := s.users[id]
-	if !exists {
-		return nil, fmt.Errorf("user with ID %d not found", id)
+// insertUser stores a user built by newUser and assigns its ID
+func (s *UserService) insertUser(ctx context.Context, user *model.User) (*model.User, error) {
+	err := s.write(ctx, func(repo repository.UserRepository) (*model.Event, error) {
+		if err := repo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return newEvent(user.ID, model.UserCreated{User: user})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrDuplicateEmail) {
+			return nil, ConflictError("user with email %s already exists", user.Email)
+		}
+		return nil, err
 	}
 
+	s.logger.InfoContext(ctx, "user created", "user_id", user.ID)
 	return user, nil
 }
 
-// UpdateUser updates an existing user
-func (s *UserService) UpdateUser(id int, req *model.UpdateUserRequest) (*model.User, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// CheckEmailAvailable returns a ConflictError when a user, deleted or not,
+// already has the email
+func (s *UserService) CheckEmailAvailable(ctx context.Context, email string) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.CheckEmailAvailable")
+	defer func() { endSpan(span, err) }()
 
-	user, exists := s.users[id]
-	if !exists {
-		return nil, fmt.Errorf("user with ID %d not found", id)
+	_, err = s.repo.GetByEmail(ctx, email)
+	switch {
+	case err == nil:
+		return ConflictError("user with email %s already exists", email)
+	case errors.Is(err, repository.ErrNotFound):
+		return nil
+	default:
+		return err
 	}
+}
+
+// GetUser retrieves a user by ID. Deleted users are only returned when includeDeleted is set.
+func (s *UserService) GetUser(ctx context.Context, id int, includeDeleted bool) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.GetUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { endSpan(span, err) }()
 
-	// Check if email already exists (if being updated)
-	if req.Email != nil && *req.Email != user.Email {
-		for _, existingUser := range s.users {
-			if existingUser.ID != id && existingUser.Email == *req.Email {
-				return nil, fmt.Errorf("user with email %s already exists", *req.Email)
-			}
+	user, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NotFoundError("user with ID %d not found", id)
 		}
+		return nil, err
 	}
 
-	// Update fields
-	if req.Email != nil {
-		user.Email = *req.Email
+	if user.DeletedAt != nil && !includeDeleted {
+		return nil, NotFoundError("user with ID %d not found", id)
 	}
-	if req.FirstName != nil {
-		user.FirstName = *req.FirstName
+
+	return user, nil
+}
+
+// UpdateOptions controls how an update, replacement or patch is applied
+type UpdateOptions struct {
+	// ExpectedVersion makes the write conditional on the user still being at
+	// this version; zero disables the check
+	ExpectedVersion int
+	// AllowStatusChange permits changing the user's status
+	AllowStatusChange bool
+}
+
+// UpdateUser updates the fields of an existing user that are set in req
+func (s *UserService) UpdateUser(ctx context.Context, id int, req *model.UpdateUserRequest, opts UpdateOptions) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.UpdateUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
 	}
-	if req.LastName != nil {
-		user.LastName = *req.LastName
+
+	return s.update(ctx, id, opts, func(user *model.User) error {
+		if req.Email != nil {
+			user.Email = *req.Email
+		}
+		if req.FirstName != nil {
+			user.FirstName = *req.FirstName
+		}
+		if req.LastName != nil {
+			user.LastName = *req.LastName
+		}
+		if req.Age != nil {
+			user.Age = *req.Age
+		}
+		if req.Phone != nil {
+			user.Phone = *req.Phone
+		}
+		if req.Status != nil {
+			user.Status = *req.Status
+		}
+		return nil
+	})
+}
+
+// ReplaceUser replaces every editable field of an existing user
+func (s *UserService) ReplaceUser(ctx context.Context, id int, req *model.ReplaceUserRequest, opts UpdateOptions) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ReplaceUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	return s.update(ctx, id, opts, func(user *model.User) error {
+		replaceUser(user, req)
+		return nil
+	})
+}
+
+// PatchUser applies a JSON merge patch or JSON patch to the editable fields of
+// an existing user. The patched user is validated like a replacement.
+func (s *UserService) PatchUser(ctx context.Context, id int, format model.PatchFormat, patch []byte, opts UpdateOptions) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.PatchUser", trace.WithAttributes(
+		attribute.Int("user.id", id),
+		attribute.String("patch.format", string(format)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	return s.update(ctx, id, opts, func(user *model.User) error {
+		req, err := applyPatch(user, format, patch)
+		if err != nil {
+			return err
+		}
+		if err := validate(req); err != nil {
+			return err
+		}
+		replaceUser(user, req)
+		return nil
+	})
+}
+
+// update loads a user, lets apply modify it and writes it back, enforcing the
+// expected version and the status change permission
+func (s *UserService) update(ctx context.Context, id int, opts UpdateOptions, apply func(user *model.User) error) (*model.User, error) {
+	user, err := s.GetUser(ctx, id, false)
+	if err != nil {
+		return nil, err
 	}
-	if req.Age != nil {
-		user.Age = *req.Age
+
+	if opts.ExpectedVersion != 0 && user.Version != opts.ExpectedVersion {
+		return nil, PreconditionFailedError("user with ID %d is at version %d, not %d", id, user.Version, opts.ExpectedVersion)
 	}
-	if req.Phone != nil {
-		user.Phone = *req.Phone
+
+	before := *user
+	if err := apply(user); err != nil {
+		return nil, err
 	}
-	if req.Status != nil {
-		user.Status = *req.Status
+	if user.Status != before.Status && !opts.AllowStatusChange {
+		return nil, ForbiddenError("Only administrators may change a user's status")
 	}
 
 	user.UpdatedAt = time.Now()
 
+	err = s.write(ctx, func(repo repository.UserRepository) (*model.Event, error) {
+		if err := repo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+
+		changes := diffUsers(&before, user)
+		if len(changes) == 0 {
+			return nil, nil
+		}
+		return newEvent(id, model.UserUpdated{User: user, Changes: changes})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, NotFoundError("user with ID %d not found", id)
+		case errors.Is(err, repository.ErrDuplicateEmail):
+			return nil, ConflictError("user with email %s already exists", user.Email)
+		case errors.Is(err, repository.ErrVersionConflict):
+			return nil, versionConflict(id, opts.ExpectedVersion)
+		}
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "user updated", "user_id", id)
 	return user, nil
 }
 
-// DeleteUser deletes a user by ID
-func (s *UserService) DeleteUser(id int) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// replaceUser copies every editable field from req to user
+func replaceUser(user *model.User, req *model.ReplaceUserRequest) {
+	user.Email = req.Email
+	user.FirstName = req.FirstName
+	user.LastName = req.LastName
+	user.Age = req.Age
+	user.Phone = req.Phone
+	user.Status = req.Status
+}
+
+// DeleteUser marks a user as deleted. The user can be restored until it is purged.
+func (s *UserService) DeleteUser(ctx context.Context, id int) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.DeleteUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { endSpan(span, err) }()
 
-	if _, exists := s.users[id]; !exists {
-		return fmt.Errorf("user with ID %d not found", id)
+	deletedAt := time.Now()
+	err = s.write(ctx, func(repo repository.UserRepository) (*model.Event, error) {
+		if err := repo.Delete(ctx, id, deletedAt); err != nil {
+			return nil, err
+		}
+		return newEvent(id, model.UserDeleted{UserID: id, DeletedAt: deletedAt})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return NotFoundError("user with ID %d not found", id)
+		}
+		return err
 	}
 
-	delete(s.users, id)
+	s.logger.InfoContext(ctx, "user deleted", "user_id", id)
 	return nil
 }
 
-// ListUsers returns a paginated list of users
-func (s *UserService) ListUsers(page, perPage int) (*model.UserListResponse, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+// versionConflict reports a user that changed between being read and written.
+// The write was conditional when expectedVersion is set, so the condition failed;
+// otherwise the caller simply lost the race and may retry.
+func versionConflict(id, expectedVersion int) error {
+	if expectedVersion != 0 {
+		return PreconditionFailedError("user with ID %d was modified concurrently", id)
+	}
+	return ConflictError("user with ID %d was modified concurrently; retry the request", id)
+}
+
+// RestoreUser undoes the deletion of a user that has not been purged yet
+func (s *UserService) RestoreUser(ctx context.Context, id int) (_ *model.User, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.RestoreUser", trace.WithAttributes(attribute.Int("user.id", id)))
+	defer func() { endSpan(span, err) }()
+
+	var user *model.User
+	err = s.write(ctx, func(repo repository.UserRepository) (*model.Event, error) {
+		before, err := repo.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.Restore(ctx, id, time.Now()); err != nil {
+			return nil, err
+		}
+		if user, err = repo.Get(ctx, id); err != nil {
+			return nil, err
+		}
+		return newEvent(id, model.UserUpdated{User: user, Changes: diffUsers(before, user)})
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NotFoundError("deleted user with ID %d not found", id)
+		}
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "user restored", "user_id", id)
+	return user, nil
+}
+
+// PurgeDeletedUsers permanently removes the users deleted longer than retention ago
+func (s *UserService) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.PurgeDeletedUsers")
+	defer func() { endSpan(span, err) }()
 
-	// Convert map to slice
-	allUsers := make([]*model.User, 0, len(s.users))
-	for _, user := range s.users {
-		allUsers = append(allUsers, user)
+	purged, err := s.repo.Purge(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
 	}
 
-	total := len(allUsers)
-	totalPages := (total + perPage - 1) / perPage
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "deleted users purged", "count", purged)
+	}
+	return purged, nil
+}
 
-	// Calculate pagination
-	start := (page - 1) * perPage
-	end := start + perPage
+// RunPurge purges deleted users past the retention period every interval until ctx is done
+func (s *UserService) RunPurge(ctx context.Context, retention, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if start >= total {
-		return &model.UserListResponse{
-			Users: []model.User{},
-			Meta: model.MetaData{
-				Page:       page,
-				PerPage:    perPage,
-				Total:      total,
-				TotalPages: totalPages,
-			},
-		}, nil
+	for {
+		if _, err := s.PurgeDeletedUsers(ctx, retention); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "failed to purge deleted users", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ListUsers returns a page of the users matching the query. Pages are addressed
+// either by page number or, when query.Cursor is set, by a cursor from a previous response.
+func (s *UserService) ListUsers(ctx context.Context, query *model.UserListQuery) (_ *model.UserListResponse, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ListUsers")
+	defer func() { endSpan(span, err) }()
+
+	opts, err := listOptions(query)
+	if err != nil {
+		return nil, err
 	}
 
-	if end > total {
-		end = total
+	if query.Cursor != "" {
+		return s.listUsersByCursor(ctx, query, opts)
 	}
 
-	// Get paginated users
-	paginatedUsers := make([]model.User, 0, end-start)
-	for i := start; i < end; i++ {
-		paginatedUsers = append(paginatedUsers, *allUsers[i])
+	opts.Limit = query.PerPage
+	opts.Offset = (query.Page - 1) * query.PerPage
+
+	users, total, err := s.repo.List(ctx, *opts)
+	if err != nil {
+		return nil, err
 	}
 
+	response := newUserListResponse(users, total, query)
+	if len(users) > 0 {
+		s.setCursors(&response.Meta, users, opts, opts.Offset > 0, opts.Offset+len(users) < total)
+	}
+
+	return response, nil
+}
+
+// ExportUsers calls fn for every user matching the query's filters, in the
+// query's sort order. Pagination fields are ignored. Users are streamed from
+// storage, so fn must not block for long.
+func (s *UserService) ExportUsers(ctx context.Context, query *model.UserListQuery, fn func(user *model.User) error) (err error) {
+	ctx, span := tracer.Start(ctx, "UserService.ExportUsers")
+	defer func() { endSpan(span, err) }()
+
+	opts, err := listOptions(query)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Stream(ctx, *opts, fn)
+}
+
+// CountUsersByStatus returns the number of users in each status
+func (s *UserService) CountUsersByStatus(ctx context.Context) (_ map[string]int, err error) {
+	ctx, span := tracer.Start(ctx, "UserService.CountUsersByStatus")
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.CountByStatus(ctx)
+}
+
+// listUsersByCursor returns the page of users adjacent to the query's cursor
+func (s *UserService) listUsersByCursor(ctx context.Context, query *model.UserListQuery, opts *repository.ListOptions) (*model.UserListResponse, error) {
+	if err := s.cursors.decode(query.Cursor, opts); err != nil {
+		return nil, ValidationError(map[string]string{"Cursor": "Invalid cursor"})
+	}
+
+	// Fetch one extra user to find out whether another page follows
+	opts.Limit = query.PerPage + 1
+
+	users, total, err := s.repo.List(ctx, *opts)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(users) > query.PerPage
+	if hasMore && opts.After != nil {
+		users = users[:query.PerPage]
+	} else if hasMore {
+		users = users[1:]
+	}
+
+	response := newUserListResponse(users, total, query)
+	response.Meta.Page = 0
+	if len(users) > 0 {
+		if opts.After != nil {
+			s.setCursors(&response.Meta, users, opts, true, hasMore)
+		} else {
+			s.setCursors(&response.Meta, users, opts, hasMore, true)
+		}
+	}
+
+	return response, nil
+}
+
+// setCursors fills in the cursors pointing to the pages around users
+func (s *UserService) setCursors(meta *model.MetaData, users []model.User, opts *repository.ListOptions, hasPrev, hasNext bool) {
+	if hasPrev {
+		meta.PrevCursor = s.cursors.encode(&users[0], opts, true)
+	}
+	if hasNext {
+		meta.NextCursor = s.cursors.encode(&users[len(users)-1], opts, false)
+	}
+}
+
+// listOptions validates a list query and converts its filter and sort to repository options
+func listOptions(query *model.UserListQuery) (*repository.ListOptions, error) {
+	if err := validate(query); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	if query.MinAge != nil && query.MaxAge != nil && *query.MinAge > *query.MaxAge {
+		fields["MaxAge"] = "Must be greater than or equal to MinAge"
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		fields["CreatedBefore"] = "Must be later than CreatedAfter"
+	}
+	if query.UpdatedAfter != nil && query.UpdatedBefore != nil && !query.UpdatedAfter.Before(*query.UpdatedBefore) {
+		fields["UpdatedBefore"] = "Must be later than UpdatedAfter"
+	}
+
+	opts := &repository.ListOptions{
+		Filter: repository.UserFilter{
+			Status:         query.Status,
+			MinAge:         query.MinAge,
+			MaxAge:         query.MaxAge,
+			CreatedAfter:   query.CreatedAfter,
+			CreatedBefore:  query.CreatedBefore,
+			UpdatedAfter:   query.UpdatedAfter,
+			UpdatedBefore:  query.UpdatedBefore,
+			Search:         query.Search,
+			IncludeDeleted: query.IncludeDeleted,
+		},
+	}
+
+	seen := make(map[string]bool)
+	for _, term := range query.Sort {
+		field := strings.TrimPrefix(term, "-")
+		if !repository.UserSortFields[field] || seen[field] {
+			fields["Sort"] = "Must be a list of distinct fields from: " + sortFieldNames()
+			break
+		}
+		seen[field] = true
+		opts.Sort = append(opts.Sort, repository.SortField{
+			Field: field,
+			Desc:  strings.HasPrefix(term, "-"),
+		})
+	}
+
+	if len(fields) > 0 {
+		return nil, ValidationError(fields)
+	}
+
+	return opts, nil
+}
+
+// sortFieldNames lists the sortable user fields for error messages
+func sortFieldNames() string {
+	names := make([]string, 0, len(repository.UserSortFields))
+	for name := range repository.UserSortFields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, " ")
+}
+
+// newUserListResponse builds a list response with page-based metadata
+func newUserListResponse(users []model.User, total int, query *model.UserListQuery) *model.UserListResponse {
 	return &model.UserListResponse{
-		Users: paginatedUsers,
+		Users: users,
 		Meta: model.MetaData{
-			Page:       page,
-			PerPage:    perPage,
+			Page:       query.Page,
+			PerPage:    query.PerPage,
 			Total:      total,
-			TotalPages: totalPages,
+			TotalPages: (total + query.PerPage - 1) / query.PerPage,
 		},
-	}, nil
+	}
 }
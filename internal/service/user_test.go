@@ -0,0 +1,386 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestService(t *testing.T, users int) *UserService {
+	t.Helper()
+
+	s := NewUserService(repository.NewMemoryUserRepository(), "test-secret", slog.New(slog.DiscardHandler))
+	for i := 0; i < users; i++ {
+		_, err := s.CreateUser(context.Background(), &model.CreateUserRequest{
+			Email:     fmt.Sprintf("user%d@example.com", i),
+			FirstName: "John",
+			LastName:  "Doe",
+			Age:       30,
+		})
+		require.NoError(t, err)
+	}
+
+	return s
+}
+
+func TestCreateAdmin(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+	req := &model.CreateUserRequest{Email: "admin@example.com", FirstName: "Ada", LastName: "Admin", Age: 40}
+
+	_, err := s.CreateAdmin(ctx, req)
+	require.ErrorIs(t, err, ErrValidation)
+
+	req.Password = "correct-horse-battery"
+	admin, err := s.CreateAdmin(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", admin.Role)
+	assert.NotEmpty(t, admin.PasswordHash)
+
+	stored, err := s.GetUser(ctx, admin.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, "admin", stored.Role)
+
+	_, err = s.CreateAdmin(ctx, &model.CreateUserRequest{Email: "user0@example.com", FirstName: "Ada", LastName: "Admin", Age: 40, Password: "correct-horse-battery"})
+	assert.ErrorIs(t, err, ErrConflict)
+}
+
+func TestListUsersCursorWalk(t *testing.T) {
+	s := newTestService(t, 7)
+
+	// Walk forward from the first offset page
+	page, err := s.ListUsers(context.Background(), &model.UserListQuery{Page: 1, PerPage: 3})
+	require.NoError(t, err)
+	assert.Empty(t, page.Meta.PrevCursor)
+
+	var seen []int
+	for {
+		for _, user := range page.Users {
+			seen = append(seen, user.ID)
+		}
+		if page.Meta.NextCursor == "" {
+			break
+		}
+		page, err = s.ListUsers(context.Background(), &model.UserListQuery{PerPage: 3, Cursor: page.Meta.NextCursor})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7}, seen)
+
+	// Walk back from the last page
+	page, err = s.ListUsers(context.Background(), &model.UserListQuery{PerPage: 3, Cursor: page.Meta.PrevCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []int{4, 5, 6}, userIDs(page.Users))
+	assert.NotEmpty(t, page.Meta.NextCursor)
+
+	page, err = s.ListUsers(context.Background(), &model.UserListQuery{PerPage: 3, Cursor: page.Meta.PrevCursor})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, userIDs(page.Users))
+	assert.Empty(t, page.Meta.PrevCursor)
+}
+
+func TestListUsersRejectsTamperedCursor(t *testing.T) {
+	s := newTestService(t, 3)
+
+	page, err := s.ListUsers(context.Background(), &model.UserListQuery{Page: 1, PerPage: 1})
+	require.NoError(t, err)
+	require.NotEmpty(t, page.Meta.NextCursor)
+
+	// A cursor signed with another secret is rejected
+	other := NewUserService(repository.NewMemoryUserRepository(), "other-secret", slog.New(slog.DiscardHandler))
+	_, err = other.ListUsers(context.Background(), &model.UserListQuery{PerPage: 1, Cursor: page.Meta.NextCursor})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	_, err = s.ListUsers(context.Background(), &model.UserListQuery{PerPage: 1, Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestListUsersSortedCursorWalk(t *testing.T) {
+	s := newTestService(t, 5)
+
+	query := model.UserListQuery{Page: 1, PerPage: 2, Sort: []string{"-id"}}
+	page, err := s.ListUsers(context.Background(), &query)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 4}, userIDs(page.Users))
+
+	query.Cursor = page.Meta.NextCursor
+	page, err = s.ListUsers(context.Background(), &query)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 2}, userIDs(page.Users))
+
+	// The cursor is bound to the sort it was issued for
+	query.Sort = []string{"email"}
+	_, err = s.ListUsers(context.Background(), &query)
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestListUsersValidatesQuery(t *testing.T) {
+	s := newTestService(t, 0)
+
+	minAge, maxAge := 40, 30
+	queries := []model.UserListQuery{
+		{Page: 1, PerPage: 10, Sort: []string{"password"}},
+		{Page: 1, PerPage: 10, Sort: []string{"age", "-age"}},
+		{Page: 1, PerPage: 10, Status: []string{"deleted"}},
+		{Page: 1, PerPage: 10, MinAge: &minAge, MaxAge: &maxAge},
+	}
+	for _, query := range queries {
+		_, err := s.ListUsers(context.Background(), &query)
+		assert.ErrorIs(t, err, ErrValidation)
+	}
+}
+
+func userIDs(users []model.User) []int {
+	ids := make([]int, 0, len(users))
+	for _, user := range users {
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+func TestDeleteAndRestoreUser(t *testing.T) {
+	s := newTestService(t, 2)
+	ctx := context.Background()
+
+	require.NoError(t, s.DeleteUser(ctx, 1))
+	assert.ErrorIs(t, s.DeleteUser(ctx, 1), ErrNotFound)
+
+	// Deleted users are hidden unless explicitly requested
+	_, err := s.GetUser(ctx, 1, false)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	user, err := s.GetUser(ctx, 1, true)
+	require.NoError(t, err)
+	assert.NotNil(t, user.DeletedAt)
+
+	page, err := s.ListUsers(ctx, &model.UserListQuery{Page: 1, PerPage: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Meta.Total)
+
+	page, err = s.ListUsers(ctx, &model.UserListQuery{Page: 1, PerPage: 10, IncludeDeleted: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Meta.Total)
+
+	_, err = s.UpdateUser(ctx, 1, &model.UpdateUserRequest{}, UpdateOptions{})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	restored, err := s.RestoreUser(ctx, 1)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	_, err = s.RestoreUser(ctx, 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPurgeDeletedUsers(t *testing.T) {
+	s := newTestService(t, 2)
+	ctx := context.Background()
+
+	require.NoError(t, s.DeleteUser(ctx, 1))
+
+	purged, err := s.PurgeDeletedUsers(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	purged, err = s.PurgeDeletedUsers(ctx, -time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = s.GetUser(ctx, 1, true)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUpdateUserExpectedVersion(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+	name := "Jane"
+
+	_, err := s.UpdateUser(ctx, 1, &model.UpdateUserRequest{FirstName: &name}, UpdateOptions{ExpectedVersion: 2})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	user, err := s.UpdateUser(ctx, 1, &model.UpdateUserRequest{FirstName: &name}, UpdateOptions{ExpectedVersion: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, user.Version)
+
+	user, err = s.UpdateUser(ctx, 1, &model.UpdateUserRequest{FirstName: &name}, UpdateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, user.Version)
+}
+
+func TestUpdateUserStatusChange(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+	status := "suspended"
+
+	_, err := s.UpdateUser(ctx, 1, &model.UpdateUserRequest{Status: &status}, UpdateOptions{})
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	user, err := s.UpdateUser(ctx, 1, &model.UpdateUserRequest{Status: &status}, UpdateOptions{AllowStatusChange: true})
+	require.NoError(t, err)
+	assert.Equal(t, "suspended", user.Status)
+
+	// Restating the current status is not a change
+	_, err = s.UpdateUser(ctx, 1, &model.UpdateUserRequest{Status: &status}, UpdateOptions{})
+	assert.NoError(t, err)
+}
+
+func TestReplaceUser(t *testing.T) {
+	s := newTestService(t, 1)
+	ctx := context.Background()
+
+	_, err := s.PatchUser(ctx, 1, model.MergePatch, []byte(`{"phone":"+1234567890"}`), UpdateOptions{})
+	require.NoError(t, err)
+
+	user, err := s.ReplaceUser(ctx, 1, &model.ReplaceUserRequest{
+		Email:     "jane@example.com",
+		FirstName: "Jane",
+		LastName:  "Roe",
+		Age:       40,
+		Status:    "active",
+	}, UpdateOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", user.Email)
+	assert.Equal(t, "Jane", user.FirstName)
+	assert.Empty(t, user.Phone)
+
+	_, err = s.ReplaceUser(ctx, 1, &model.ReplaceUserRequest{Email: "jane@example.com"}, UpdateOptions{})
+	assert.ErrorIs(t, err, ErrValidation)
+}
+
+func TestPatchUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		format model.PatchFormat
+		patch  string
+		opts   UpdateOptions
+		err    error
+		check  func(t *testing.T, user *model.User)
+	}{
+		{
+			name:   "merge patch sets fields",
+			format: model.MergePatch,
+			patch:  `{"first_name":"Jane","phone":"+1234567890"}`,
+			check: func(t *testing.T, user *model.User) {
+				assert.Equal(t, "Jane", user.FirstName)
+				assert.Equal(t, "Doe", user.LastName)
+				assert.Equal(t, "+1234567890", user.Phone)
+			},
+		},
+		{
+			name:   "merge patch null clears optional field",
+			format: model.MergePatch,
+			patch:  `{"phone":null}`,
+			check: func(t *testing.T, user *model.User) {
+				assert.Empty(t, user.Phone)
+			},
+		},
+		{
+			name:   "merge patch null on required field",
+			format: model.MergePatch,
+			patch:  `{"email":null}`,
+			err:    ErrValidation,
+		},
+		{
+			name:   "merge patch unknown field",
+			format: model.MergePatch,
+			patch:  `{"role":"admin"}`,
+			err:    ErrValidation,
+		},
+		{
+			name:   "merge patch invalid value",
+			format: model.MergePatch,
+			patch:  `{"age":500}`,
+			err:    ErrValidation,
+		},
+		{
+			name:   "malformed merge patch",
+			format: model.MergePatch,
+			patch:  `{`,
+			err:    ErrValidation,
+		},
+		{
+			name:   "json patch replace",
+			format: model.JSONPatch,
+			patch:  `[{"op":"test","path":"/first_name","value":"John"},{"op":"replace","path":"/last_name","value":"Smith"}]`,
+			check: func(t *testing.T, user *model.User) {
+				assert.Equal(t, "Smith", user.LastName)
+			},
+		},
+		{
+			name:   "json patch failed test",
+			format: model.JSONPatch,
+			patch:  `[{"op":"test","path":"/first_name","value":"Jane"},{"op":"replace","path":"/last_name","value":"Smith"}]`,
+			err:    ErrConflict,
+		},
+		{
+			name:   "json patch missing path",
+			format: model.JSONPatch,
+			patch:  `[{"op":"remove","path":"/phone"}]`,
+			err:    ErrValidation,
+		},
+		{
+			name:   "json patch status change forbidden",
+			format: model.JSONPatch,
+			patch:  `[{"op":"replace","path":"/status","value":"suspended"}]`,
+			err:    ErrForbidden,
+		},
+		{
+			name:   "json patch status change allowed",
+			format: model.JSONPatch,
+			patch:  `[{"op":"replace","path":"/status","value":"suspended"}]`,
+			opts:   UpdateOptions{AllowStatusChange: true},
+			check: func(t *testing.T, user *model.User) {
+				assert.Equal(t, "suspended", user.Status)
+			},
+		},
+		{
+			name:   "stale version",
+			format: model.MergePatch,
+			patch:  `{"first_name":"Jane"}`,
+			opts:   UpdateOptions{ExpectedVersion: 2},
+			err:    ErrPreconditionFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, 1)
+
+			user, err := s.PatchUser(context.Background(), 1, tt.format, []byte(tt.patch), tt.opts)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 2, user.Version)
+			tt.check(t, user)
+		})
+	}
+}
+
+func TestExportUsers(t *testing.T) {
+	s := newTestService(t, 3)
+	ctx := context.Background()
+	require.NoError(t, s.DeleteUser(ctx, 2))
+
+	var emails []string
+	err := s.ExportUsers(ctx, &model.UserListQuery{Sort: []string{"-email"}}, func(user *model.User) error {
+		emails = append(emails, user.Email)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user2@example.com", "user0@example.com"}, emails)
+
+	err = s.ExportUsers(ctx, &model.UserListQuery{Sort: []string{"password_hash"}}, func(*model.User) error {
+		t.Fatal("invalid query must not be exported")
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrValidation)
+}
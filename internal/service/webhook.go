@@ -0,0 +1,478 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+)
+
+// webhookSecretPrefix marks generated webhook secrets so they are recognisable in configs
+const webhookSecretPrefix = "whsec_"
+
+// errNonPublicAddress is returned when a delivery would connect to an address
+// that is not publicly routable
+var errNonPublicAddress = errors.New("address is not publicly routable")
+
+// nonPublicPrefixes are the ranges netip.Addr does not classify that webhooks
+// must not reach either: "this network" and the carrier-grade NAT shared space
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+}
+
+// WebhookService manages webhook subscriptions and delivers user events to
+// them. It is an event sink: each event is queued once per subscribed webhook
+// and sent by the queue's workers, which retry failed deliveries.
+type WebhookService struct {
+	repo   repository.WebhookRepository
+	queue  *queue.Queue
+	cfg    config.WebhooksConfig
+	client *http.Client
+	logger *slog.Logger
+}
+
+// webhookTask is the queue payload of a delivery
+type webhookTask struct {
+	WebhookID string       `json:"webhook_id"`
+	Event     *model.Event `json:"event"`
+}
+
+// NewWebhookService creates a new webhook service that stores subscriptions
+// in repo and delivers events on q
+func NewWebhookService(repo repository.WebhookRepository, q *queue.Queue, cfg config.WebhooksConfig, logger *slog.Logger) *WebhookService {
+	s := &WebhookService{
+		repo:   repo,
+		queue:  q,
+		cfg:    cfg,
+		client: newWebhookClient(cfg),
+		logger: logger,
+	}
+	q.Handle(model.JobTypeWebhookDelivery, s.deliver)
+	return s
+}
+
+// newWebhookClient creates the HTTP client deliveries are sent with. Unless
+// private networks are allowed it connects directly, without a proxy, and
+// checks every address it dials, so a webhook host that is re-pointed at an
+// internal address after it was saved is still refused.
+func newWebhookClient(cfg config.WebhooksConfig) *http.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = dialPublicOnly
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		// A redirect counts as a failed delivery rather than resending the event elsewhere
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublicOnly is a net.Dialer Control function refusing connections to
+// addresses that are not publicly routable. It sees the resolved address.
+func dialPublicOnly(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddress(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, addrPort.Addr())
+	}
+	return nil
+}
+
+// isPublicAddress reports whether addr is routable on the internet. Loopback,
+// private, link-local (including the 169.254.169.254 metadata service),
+// multicast and unspecified addresses are not.
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkURL rejects a webhook URL whose host is or resolves to an address that
+// is not publicly routable, unless private networks are allowed
+func (s *WebhookService) checkURL(ctx context.Context, rawURL string) error {
+	if s.cfg.AllowPrivateNetworks {
+		return nil
+	}
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ValidationError(map[string]string{"url": "url must be a valid HTTP URL"})
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return ValidationError(map[string]string{"url": "url host could not be resolved"})
+	}
+
+	for _, addr := range addrs {
+		if !isPublicAddress(addr) {
+			return ValidationError(map[string]string{"url": "url must not point to a loopback, private or link-local address"})
+		}
+	}
+	return nil
+}
+
+// CreateWebhook subscribes a URL to user events. The returned secret cannot be retrieved again.
+func (s *WebhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (_ *model.CreatedWebhook, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.CreateWebhook")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	if err := s.checkURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		token, err := randomToken()
+		if err != nil {
+			return nil, err
+		}
+		secret = webhookSecretPrefix + token
+	}
+
+	events := req.Events
+	if events == nil {
+		events = []model.EventType{}
+	}
+
+	now := time.Now()
+	webhook := model.Webhook{
+		ID:        newTokenID(),
+		URL:       req.URL,
+		Secret:    secret,
+		Events:    events,
+		Active:    true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.Create(ctx, &webhook); err != nil {
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "webhook created", "webhook_id", webhook.ID, "url", webhook.URL, "events", webhook.Events)
+	return &model.CreatedWebhook{Webhook: webhook, Secret: secret}, nil
+}
+
+// ListWebhooks returns every webhook, including disabled ones
+func (s *WebhookService) ListWebhooks(ctx context.Context) (_ []model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListWebhooks")
+	defer func() { endSpan(span, err) }()
+
+	return s.repo.List(ctx)
+}
+
+// GetWebhook retrieves a webhook by ID
+func (s *WebhookService) GetWebhook(ctx context.Context, id string) (_ *model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.GetWebhook")
+	defer func() { endSpan(span, err) }()
+
+	return s.getWebhook(ctx, id)
+}
+
+// UpdateWebhook changes the fields set in req. Enabling a webhook clears its
+// failures, including after it was disabled automatically.
+func (s *WebhookService) UpdateWebhook(ctx context.Context, id string, req *model.UpdateWebhookRequest) (_ *model.Webhook, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.UpdateWebhook")
+	defer func() { endSpan(span, err) }()
+
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	if req.URL != nil {
+		if err := s.checkURL(ctx, *req.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook, err := s.getWebhook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		webhook.Events = append([]model.EventType{}, *req.Events...)
+	}
+	if req.Secret != nil {
+		webhook.Secret = *req.Secret
+	}
+	if req.Active != nil {
+		if *req.Active && !webhook.Active {
+			webhook.FailureCount = 0
+			webhook.DisabledAt = nil
+		}
+		webhook.Active = *req.Active
+	}
+	webhook.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, webhook); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NotFoundError("webhook with ID %s not found", id)
+		}
+		return nil, err
+	}
+
+	s.logger.InfoContext(ctx, "webhook updated", "webhook_id", webhook.ID, "active", webhook.Active)
+	return webhook, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log. Queued deliveries to it are dropped.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) (err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.DeleteWebhook")
+	defer func() { endSpan(span, err) }()
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return NotFoundError("webhook with ID %s not found", id)
+		}
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "webhook deleted", "webhook_id", id)
+	return nil
+}
+
+// ListDeliveries returns up to limit of a webhook's latest delivery attempts, newest first
+func (s *WebhookService) ListDeliveries(ctx context.Context, id string, limit int) (_ []model.WebhookDelivery, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.ListDeliveries")
+	defer func() { endSpan(span, err) }()
+
+	if _, err := s.getWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListDeliveries(ctx, id, limit)
+}
+
+// PurgeDeliveries removes the delivery logs older than the retention period
+func (s *WebhookService) PurgeDeliveries(ctx context.Context) (_ int, err error) {
+	ctx, span := tracer.Start(ctx, "WebhookService.PurgeDeliveries")
+	defer func() { endSpan(span, err) }()
+
+	purged, err := s.repo.PurgeDeliveries(ctx, time.Now().Add(-s.cfg.DeliveryRetention))
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "purged webhook deliveries", "count", purged)
+	}
+	return purged, nil
+}
+
+// RunPurge purges old delivery logs every interval until ctx is done
+func (s *WebhookService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeDeliveries(ctx); err != nil && ctx.Err() == nil {
+			s.logger.ErrorContext(ctx, "failed to purge webhook deliveries", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Name returns "webhook"
+func (s *WebhookService) Name() string {
+	return "webhook"
+}
+
+// Publish queues a delivery of every event to each active webhook subscribed to its type
+func (s *WebhookService) Publish(ctx context.Context, events []*model.Event) error {
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		for i := range webhooks {
+			if !webhooks[i].Subscribes(event.Type) {
+				continue
+			}
+
+			task := webhookTask{WebhookID: webhooks[i].ID, Event: event}
+			if _, err := s.queue.Enqueue(ctx, model.JobTypeWebhookDelivery, task, queue.MaxAttempts(s.cfg.MaxAttempts)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deliver is the queue handler for webhook deliveries. Deliveries to webhooks
+// that were deleted, disabled or unsubscribed since the event was queued are dropped.
+func (s *WebhookService) deliver(ctx context.Context, queued *model.QueueJob) error {
+	var task webhookTask
+	if err := json.Unmarshal(queued.Payload, &task); err != nil {
+		return queue.Permanent(err)
+	}
+	if task.Event == nil {
+		return queue.Permanent(errors.New("webhook delivery without an event"))
+	}
+
+	webhook, err := s.repo.Get(ctx, task.WebhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !webhook.Subscribes(task.Event.Type) {
+		return nil
+	}
+
+	delivery := s.send(ctx, webhook, task.Event)
+	delivery.Attempt = queued.Attempts
+
+	// The attempt is logged even when the delivery was interrupted
+	updated, err := s.repo.RecordDelivery(context.WithoutCancel(ctx), delivery, s.cfg.DisableAfter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		s.logger.ErrorContext(ctx, "failed to record webhook delivery", "webhook_id", webhook.ID, "error", err)
+		updated = webhook
+	}
+
+	if delivery.Success {
+		return nil
+	}
+	if !updated.Active {
+		// Only the attempt that reached the limit reports it; deliveries in flight may fail after it
+		if updated.FailureCount == s.cfg.DisableAfter {
+			s.logger.WarnContext(ctx, "webhook disabled after repeated delivery failures",
+				"webhook_id", webhook.ID, "failures", updated.FailureCount)
+		}
+		return nil
+	}
+	return errors.New(delivery.Error)
+}
+
+// send POSTs an event to a webhook and describes the outcome
+func (s *WebhookService) send(ctx context.Context, webhook *model.Webhook, event *model.Event) *model.WebhookDelivery {
+	started := time.Now()
+	delivery := &model.WebhookDelivery{
+		ID:        newTokenID(),
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		EventType: event.Type,
+		CreatedAt: started,
+	}
+
+	status, err := s.post(ctx, webhook, event, started)
+	delivery.DurationMS = time.Since(started).Milliseconds()
+	delivery.StatusCode = status
+
+	switch {
+	case err != nil:
+		delivery.Error = err.Error()
+	case status < 200 || status > 299:
+		delivery.Error = fmt.Sprintf("unexpected status %d", status)
+	default:
+		delivery.Success = true
+	}
+
+	return delivery
+}
+
+// post sends the signed event and returns the response status
+func (s *WebhookService) post(ctx context.Context, webhook *model.Webhook, event *model.Event, at time.Time) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID)
+	req.Header.Set("X-Event-Type", string(event.Type))
+	req.Header.Set("X-Signature", WebhookSignature(webhook.Secret, at, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	return resp.StatusCode, nil
+}
+
+// WebhookSignature returns the X-Signature header of a delivery sent at the
+// given time: "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">"
+func WebhookSignature(secret string, at time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// getWebhook retrieves a webhook, mapping a missing one to a not found error
+func (s *WebhookService) getWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	webhook, err := s.repo.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, NotFoundError("webhook with ID %s not found", id)
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
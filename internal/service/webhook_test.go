@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/your-org/your-project/internal/config"
+	"github.com/your-org/your-project/internal/model"
+	"github.com/your-org/your-project/internal/queue"
+	"github.com/your-org/your-project/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestWebhookService creates a webhook service whose queue runs until the test ends
+func newTestWebhookService(t *testing.T, cfg config.WebhooksConfig) *WebhookService {
+	t.Helper()
+
+	q := queue.New(repository.NewMemoryQueueRepository(), config.QueueConfig{
+		Workers:         2,
+		PollInterval:    5 * time.Millisecond,
+		MaxAttempts:     1,
+		RetryBackoff:    time.Millisecond,
+		MaxRetryBackoff: time.Millisecond,
+		JobTimeout:      time.Minute,
+	}, slog.New(slog.DiscardHandler))
+	s := NewWebhookService(repository.NewMemoryWebhookRepository(), q, cfg, slog.New(slog.DiscardHandler))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return s
+}
+
+func TestWebhookDelivery(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	var (
+		mutex    sync.Mutex
+		requests []received
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mutex.Lock()
+		defer mutex.Unlock()
+		requests = append(requests, received{header: r.Header, body: body})
+	}))
+	defer server.Close()
+
+	s := newTestWebhookService(t, config.WebhooksConfig{MaxAttempts: 3, Timeout: time.Second, DisableAfter: 5, AllowPrivateNetworks: true})
+	ctx := context.Background()
+
+	created, err := s.CreateWebhook(ctx, &model.CreateWebhookRequest{
+		URL:    server.URL,
+		Events: []model.EventType{model.EventUserCreated},
+		Secret: "0123456789abcdef",
+	})
+	require.NoError(t, err)
+	_, err = s.CreateWebhook(ctx, &model.CreateWebhookRequest{URL: server.URL + "/deleted", Events: []model.EventType{model.EventUserDeleted}})
+	require.NoError(t, err)
+
+	event := &model.Event{ID: "e1", Type: model.EventUserCreated, UserID: 1, OccurredAt: time.Now(), Data: json.RawMessage(`{"user":{"id":1}}`)}
+	require.NoError(t, s.Publish(ctx, []*model.Event{event}))
+
+	// Only the webhook subscribed to user.created receives the event
+	require.Eventually(t, func() bool {
+		deliveries, err := s.ListDeliveries(ctx, created.ID, 10)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, requests, 1)
+	got := requests[0]
+	assert.Equal(t, "e1", got.header.Get("X-Event-ID"))
+	assert.Equal(t, "user.created", got.header.Get("X-Event-Type"))
+
+	var sent model.Event
+	require.NoError(t, json.Unmarshal(got.body, &sent))
+	assert.Equal(t, "e1", sent.ID)
+	assert.JSONEq(t, `{"user":{"id":1}}`, string(sent.Data))
+
+	// The signature covers the timestamp and the body
+	signature := got.header.Get("X-Signature")
+	timestamp, _, ok := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	require.True(t, ok)
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, WebhookSignature("0123456789abcdef", time.Unix(unix, 0), got.body), signature)
+	assert.NotEqual(t, WebhookSignature("another-secret-value", time.Unix(unix, 0), got.body), signature)
+
+	deliveries, err := s.ListDeliveries(ctx, created.ID, 10)
+	require.NoError(t, err)
+	assert.True(t, deliveries[0].Success)
+	assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+	assert.Equal(t, 1, deliveries[0].Attempt)
+}
+
+func TestWebhookRetriesAndDisables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	s := newTestWebhookService(t, config.WebhooksConfig{MaxAttempts: 3, Timeout: time.Second, DisableAfter: 4, AllowPrivateNetworks: true})
+	ctx := context.Background()
+
+	created, err := s.CreateWebhook(ctx, &model.CreateWebhookRequest{URL: server.URL})
+	require.NoError(t, err)
+
+	// Every event is tried three times; the fourth failure in a row disables the webhook
+	require.NoError(t, s.Publish(ctx, []*model.Event{{ID: "e1", Type: model.EventUserCreated}}))
+	require.Eventually(t, func() bool {
+		deliveries, err := s.ListDeliveries(ctx, created.ID, 10)
+		return err == nil && len(deliveries) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, s.Publish(ctx, []*model.Event{{ID: "e2", Type: model.EventUserUpdated}}))
+	require.Eventually(t, func() bool {
+		webhook, err := s.GetWebhook(ctx, created.ID)
+		return err == nil && !webhook.Active
+	}, time.Second, 5*time.Millisecond)
+
+	webhook, err := s.GetWebhook(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, webhook.FailureCount)
+	assert.NotNil(t, webhook.DisabledAt)
+
+	deliveries, err := s.ListDeliveries(ctx, created.ID, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 4)
+	assert.Equal(t, "e2", deliveries[0].EventID)
+	assert.Equal(t, "unexpected status 500", deliveries[0].Error)
+	assert.Equal(t, []int{1, 3, 2, 1}, []int{deliveries[0].Attempt, deliveries[1].Attempt, deliveries[2].Attempt, deliveries[3].Attempt})
+
+	// Disabled webhooks receive nothing until they are enabled again, which clears their failures
+	require.NoError(t, s.Publish(ctx, []*model.Event{{ID: "e3", Type: model.EventUserCreated}}))
+	active := true
+	webhook, err = s.UpdateWebhook(ctx, created.ID, &model.UpdateWebhookRequest{Active: &active})
+	require.NoError(t, err)
+	assert.True(t, webhook.Active)
+	assert.Zero(t, webhook.FailureCount)
+	assert.Nil(t, webhook.DisabledAt)
+
+	deliveries, err = s.ListDeliveries(ctx, created.ID, 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 4)
+}
+
+func TestWebhookRejectsNonPublicAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	s := newTestWebhookService(t, config.WebhooksConfig{MaxAttempts: 1, Timeout: time.Second})
+	ctx := context.Background()
+
+	for _, url := range []string{
+		server.URL,
+		"http://localhost/hook",
+		"http://[::1]/hook",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		_, err := s.CreateWebhook(ctx, &model.CreateWebhookRequest{URL: url})
+		assert.ErrorIs(t, err, ErrValidation, url)
+	}
+
+	created, err := s.CreateWebhook(ctx, &model.CreateWebhookRequest{URL: "https://93.184.216.34/hook"})
+	require.NoError(t, err)
+	internal := "http://127.0.0.1/hook"
+	_, err = s.UpdateWebhook(ctx, created.ID, &model.UpdateWebhookRequest{URL: &internal})
+	assert.ErrorIs(t, err, ErrValidation)
+
+	// A host that resolves to an internal address after it was checked is refused when dialled
+	webhook := &model.Webhook{ID: "rebound", URL: server.URL, Events: []model.EventType{}, Active: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	require.NoError(t, s.repo.Create(ctx, webhook))
+	require.NoError(t, s.Publish(ctx, []*model.Event{{ID: "e1", Type: model.EventUserCreated}}))
+	require.Eventually(t, func() bool {
+		deliveries, err := s.ListDeliveries(ctx, webhook.ID, 10)
+		return err == nil && len(deliveries) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	deliveries, err := s.ListDeliveries(ctx, webhook.ID, 10)
+	require.NoError(t, err)
+	assert.False(t, deliveries[0].Success)
+	assert.Contains(t, deliveries[0].Error, "not publicly routable")
+}